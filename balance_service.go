@@ -2,14 +2,7 @@ package gocardless
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-
-	"github.com/google/go-querystring/query"
 )
 
 // BalanceService manages balances
@@ -65,77 +58,17 @@ type BalanceListResult struct {
 // list of balances for a given creditor. This endpoint is rate limited to 60
 // requests per minute.
 func (s *BalanceServiceImpl) List(ctx context.Context, p BalanceListParams, opts ...RequestOption) (*BalanceListResult, error) {
-	uri, err := url.Parse(fmt.Sprintf(s.config.Endpoint() + "/balances"))
-	if err != nil {
-		return nil, err
-	}
-
-	o := &requestOptions{
-		retries: 3,
-	}
-	for _, opt := range opts {
-		err := opt(o)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	var body io.Reader
-
-	v, err := query.Values(p)
-	if err != nil {
-		return nil, err
-	}
-	uri.RawQuery = v.Encode()
-
-	req, err := http.NewRequest("GET", uri.String(), body)
-	if err != nil {
-		return nil, err
-	}
-	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", "Bearer "+s.config.Token())
-	req.Header.Set("GoCardless-Version", "2015-07-06")
-	req.Header.Set("GoCardless-Client-Library", "gocardless-pro-go")
-	req.Header.Set("GoCardless-Client-Version", ClientLibVersion)
-	req.Header.Set("User-Agent", userAgent)
-
-	for key, value := range o.headers {
-		req.Header.Set(key, value)
-	}
-
-	client := s.config.Client()
-	if client == nil {
-		client = http.DefaultClient
-	}
-
 	var result struct {
-		Err *APIError `json:"error"`
 		*BalanceListResult
 	}
 
-	err = try(o.retries, func() error {
-		res, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-
-		err = responseErr(res)
-		if err != nil {
-			return err
-		}
-
-		err = json.NewDecoder(res.Body).Decode(&result)
-		if err != nil {
-			return err
-		}
-
-		if result.Err != nil {
-			return result.Err
-		}
-
-		return nil
-	})
+	err := execute(ctx, s.config, &apiRequest{
+		service: "balances",
+		action:  "list",
+		method:  "GET",
+		path:    "/balances",
+		query:   p,
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -172,88 +105,12 @@ func (c *BalanceListPagingIterator) Value(ctx context.Context) (*BalanceListResu
 	p := c.params
 	p.After = c.cursor
 
-	uri, err := url.Parse(fmt.Sprintf(s.config.Endpoint() + "/balances"))
-
+	response, err := s.List(ctx, p, c.requestOptions...)
 	if err != nil {
 		return nil, err
 	}
 
-	o := &requestOptions{
-		retries: 3,
-	}
-	for _, opt := range c.requestOptions {
-		err := opt(o)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	var body io.Reader
-
-	v, err := query.Values(p)
-	if err != nil {
-		return nil, err
-	}
-	uri.RawQuery = v.Encode()
-
-	req, err := http.NewRequest("GET", uri.String(), body)
-	if err != nil {
-		return nil, err
-	}
-
-	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", "Bearer "+s.config.Token())
-	req.Header.Set("GoCardless-Version", "2015-07-06")
-	req.Header.Set("GoCardless-Client-Library", "gocardless-pro-go")
-	req.Header.Set("GoCardless-Client-Version", ClientLibVersion)
-	req.Header.Set("User-Agent", userAgent)
-
-	for key, value := range o.headers {
-		req.Header.Set(key, value)
-	}
-	client := s.config.Client()
-	if client == nil {
-		client = http.DefaultClient
-	}
-
-	var result struct {
-		Err *APIError `json:"error"`
-		*BalanceListResult
-	}
-
-	err = try(o.retries, func() error {
-		res, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-
-		err = responseErr(res)
-
-		if err != nil {
-			return err
-		}
-
-		err = json.NewDecoder(res.Body).Decode(&result)
-		if err != nil {
-			return err
-		}
-
-		if result.Err != nil {
-			return result.Err
-		}
-
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	if result.BalanceListResult == nil {
-		return nil, errors.New("missing result")
-	}
-
-	c.response = result.BalanceListResult
+	c.response = response
 	c.cursor = c.response.Meta.Cursors.After
 	return c.response, nil
 }
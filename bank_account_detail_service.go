@@ -2,12 +2,8 @@ package gocardless
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 )
 
 // BankAccountDetailService manages bank_account_details
@@ -41,72 +37,17 @@ type BankAccountDetailGetParams struct {
 // (https://developer.gocardless.com/gc-embed/bank-details-access#public_key_setup)
 // for more details.
 func (s *BankAccountDetailServiceImpl) Get(ctx context.Context, identity string, p BankAccountDetailGetParams, opts ...RequestOption) (*BankAccountDetail, error) {
-	uri, err := url.Parse(fmt.Sprintf(s.config.Endpoint()+"/bank_account_details/%v",
-		identity))
-	if err != nil {
-		return nil, err
-	}
-
-	o := &requestOptions{
-		retries: 3,
-	}
-	for _, opt := range opts {
-		err := opt(o)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	var body io.Reader
-
-	req, err := http.NewRequest("GET", uri.String(), body)
-	if err != nil {
-		return nil, err
-	}
-	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", "Bearer "+s.config.Token())
-	req.Header.Set("GoCardless-Version", "2015-07-06")
-	req.Header.Set("GoCardless-Client-Library", "gocardless-pro-go")
-	req.Header.Set("GoCardless-Client-Version", ClientLibVersion)
-	req.Header.Set("User-Agent", userAgent)
-
-	for key, value := range o.headers {
-		req.Header.Set(key, value)
-	}
-
-	client := s.config.Client()
-	if client == nil {
-		client = http.DefaultClient
-	}
-
 	var result struct {
-		Err               *APIError          `json:"error"`
 		BankAccountDetail *BankAccountDetail `json:"bank_account_details"`
 	}
 
-	err = try(o.retries, func() error {
-		res, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-
-		err = responseErr(res)
-		if err != nil {
-			return err
-		}
-
-		err = json.NewDecoder(res.Body).Decode(&result)
-		if err != nil {
-			return err
-		}
-
-		if result.Err != nil {
-			return result.Err
-		}
-
-		return nil
-	})
+	err := execute(ctx, s.config, &apiRequest{
+		service:  "bank_account_details",
+		action:   "get",
+		method:   "GET",
+		path:     fmt.Sprintf("/bank_account_details/%v", identity),
+		identity: identity,
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
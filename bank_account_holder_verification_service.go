@@ -1,14 +1,9 @@
 package gocardless
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 )
 
 // BankAccountHolderVerificationService manages bank_account_holder_verifications
@@ -46,85 +41,20 @@ type BankAccountHolderVerificationCreateParams struct {
 // This endpoint allows partner merchants to create Confirmation of Payee checks
 // on customer bank accounts before sending outbound payments.
 func (s *BankAccountHolderVerificationServiceImpl) Create(ctx context.Context, p BankAccountHolderVerificationCreateParams, opts ...RequestOption) (*BankAccountHolderVerification, error) {
-	uri, err := url.Parse(fmt.Sprintf(s.config.Endpoint() + "/bank_account_holder_verifications"))
-	if err != nil {
-		return nil, err
-	}
-
-	o := &requestOptions{
-		retries: 3,
-	}
-	for _, opt := range opts {
-		err := opt(o)
-		if err != nil {
-			return nil, err
-		}
-	}
-	if o.idempotencyKey == "" {
-		o.idempotencyKey = NewIdempotencyKey()
-	}
-
-	var body io.Reader
-
-	var buf bytes.Buffer
-	err = json.NewEncoder(&buf).Encode(map[string]interface{}{
-		"bank_account_holder_verifications": p,
-	})
-	if err != nil {
-		return nil, err
-	}
-	body = &buf
-
-	req, err := http.NewRequest("POST", uri.String(), body)
-	if err != nil {
-		return nil, err
-	}
-	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", "Bearer "+s.config.Token())
-	req.Header.Set("GoCardless-Version", "2015-07-06")
-	req.Header.Set("GoCardless-Client-Library", "gocardless-pro-go")
-	req.Header.Set("GoCardless-Client-Version", ClientLibVersion)
-	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Idempotency-Key", o.idempotencyKey)
-
-	for key, value := range o.headers {
-		req.Header.Set(key, value)
-	}
-
-	client := s.config.Client()
-	if client == nil {
-		client = http.DefaultClient
-	}
-
 	var result struct {
-		Err                           *APIError                      `json:"error"`
 		BankAccountHolderVerification *BankAccountHolderVerification `json:"bank_account_holder_verifications"`
 	}
 
-	err = try(o.retries, func() error {
-		res, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-
-		err = responseErr(res)
-		if err != nil {
-			return err
-		}
-
-		err = json.NewDecoder(res.Body).Decode(&result)
-		if err != nil {
-			return err
-		}
-
-		if result.Err != nil {
-			return result.Err
-		}
-
-		return nil
-	})
+	err := execute(ctx, s.config, &apiRequest{
+		service: "bank_account_holder_verifications",
+		action:  "create",
+		method:  "POST",
+		path:    "/bank_account_holder_verifications",
+		getPath: "/bank_account_holder_verifications/%v",
+		body: map[string]interface{}{
+			"bank_account_holder_verifications": p,
+		},
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -139,72 +69,17 @@ func (s *BankAccountHolderVerificationServiceImpl) Create(ctx context.Context, p
 // Get
 // Fetches a bank account holder verification by ID.
 func (s *BankAccountHolderVerificationServiceImpl) Get(ctx context.Context, identity string, opts ...RequestOption) (*BankAccountHolderVerification, error) {
-	uri, err := url.Parse(fmt.Sprintf(s.config.Endpoint()+"/bank_account_holder_verifications/%v",
-		identity))
-	if err != nil {
-		return nil, err
-	}
-
-	o := &requestOptions{
-		retries: 3,
-	}
-	for _, opt := range opts {
-		err := opt(o)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	var body io.Reader
-
-	req, err := http.NewRequest("GET", uri.String(), body)
-	if err != nil {
-		return nil, err
-	}
-	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", "Bearer "+s.config.Token())
-	req.Header.Set("GoCardless-Version", "2015-07-06")
-	req.Header.Set("GoCardless-Client-Library", "gocardless-pro-go")
-	req.Header.Set("GoCardless-Client-Version", ClientLibVersion)
-	req.Header.Set("User-Agent", userAgent)
-
-	for key, value := range o.headers {
-		req.Header.Set(key, value)
-	}
-
-	client := s.config.Client()
-	if client == nil {
-		client = http.DefaultClient
-	}
-
 	var result struct {
-		Err                           *APIError                      `json:"error"`
 		BankAccountHolderVerification *BankAccountHolderVerification `json:"bank_account_holder_verifications"`
 	}
 
-	err = try(o.retries, func() error {
-		res, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-
-		err = responseErr(res)
-		if err != nil {
-			return err
-		}
-
-		err = json.NewDecoder(res.Body).Decode(&result)
-		if err != nil {
-			return err
-		}
-
-		if result.Err != nil {
-			return result.Err
-		}
-
-		return nil
-	})
+	err := execute(ctx, s.config, &apiRequest{
+		service:  "bank_account_holder_verifications",
+		action:   "get",
+		method:   "GET",
+		path:     fmt.Sprintf("/bank_account_holder_verifications/%v", identity),
+		identity: identity,
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
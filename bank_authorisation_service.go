@@ -1,14 +1,9 @@
 package gocardless
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 )
 
 // BankAuthorisationService manages bank_authorisations
@@ -53,85 +48,20 @@ type BankAuthorisationCreateParams struct {
 // Create
 // Create a Bank Authorisation.
 func (s *BankAuthorisationServiceImpl) Create(ctx context.Context, p BankAuthorisationCreateParams, opts ...RequestOption) (*BankAuthorisation, error) {
-	uri, err := url.Parse(fmt.Sprintf(s.config.Endpoint() + "/bank_authorisations"))
-	if err != nil {
-		return nil, err
-	}
-
-	o := &requestOptions{
-		retries: 3,
-	}
-	for _, opt := range opts {
-		err := opt(o)
-		if err != nil {
-			return nil, err
-		}
-	}
-	if o.idempotencyKey == "" {
-		o.idempotencyKey = NewIdempotencyKey()
-	}
-
-	var body io.Reader
-
-	var buf bytes.Buffer
-	err = json.NewEncoder(&buf).Encode(map[string]interface{}{
-		"bank_authorisations": p,
-	})
-	if err != nil {
-		return nil, err
-	}
-	body = &buf
-
-	req, err := http.NewRequest("POST", uri.String(), body)
-	if err != nil {
-		return nil, err
-	}
-	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", "Bearer "+s.config.Token())
-	req.Header.Set("GoCardless-Version", "2015-07-06")
-	req.Header.Set("GoCardless-Client-Library", "gocardless-pro-go")
-	req.Header.Set("GoCardless-Client-Version", ClientLibVersion)
-	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Idempotency-Key", o.idempotencyKey)
-
-	for key, value := range o.headers {
-		req.Header.Set(key, value)
-	}
-
-	client := s.config.Client()
-	if client == nil {
-		client = http.DefaultClient
-	}
-
 	var result struct {
-		Err               *APIError          `json:"error"`
 		BankAuthorisation *BankAuthorisation `json:"bank_authorisations"`
 	}
 
-	err = try(o.retries, func() error {
-		res, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-
-		err = responseErr(res)
-		if err != nil {
-			return err
-		}
-
-		err = json.NewDecoder(res.Body).Decode(&result)
-		if err != nil {
-			return err
-		}
-
-		if result.Err != nil {
-			return result.Err
-		}
-
-		return nil
-	})
+	err := execute(ctx, s.config, &apiRequest{
+		service: "bank_authorisations",
+		action:  "create",
+		method:  "POST",
+		path:    "/bank_authorisations",
+		getPath: "/bank_authorisations/%v",
+		body: map[string]interface{}{
+			"bank_authorisations": p,
+		},
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -146,72 +76,17 @@ func (s *BankAuthorisationServiceImpl) Create(ctx context.Context, p BankAuthori
 // Get
 // Get a single bank authorisation.
 func (s *BankAuthorisationServiceImpl) Get(ctx context.Context, identity string, opts ...RequestOption) (*BankAuthorisation, error) {
-	uri, err := url.Parse(fmt.Sprintf(s.config.Endpoint()+"/bank_authorisations/%v",
-		identity))
-	if err != nil {
-		return nil, err
-	}
-
-	o := &requestOptions{
-		retries: 3,
-	}
-	for _, opt := range opts {
-		err := opt(o)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	var body io.Reader
-
-	req, err := http.NewRequest("GET", uri.String(), body)
-	if err != nil {
-		return nil, err
-	}
-	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", "Bearer "+s.config.Token())
-	req.Header.Set("GoCardless-Version", "2015-07-06")
-	req.Header.Set("GoCardless-Client-Library", "gocardless-pro-go")
-	req.Header.Set("GoCardless-Client-Version", ClientLibVersion)
-	req.Header.Set("User-Agent", userAgent)
-
-	for key, value := range o.headers {
-		req.Header.Set(key, value)
-	}
-
-	client := s.config.Client()
-	if client == nil {
-		client = http.DefaultClient
-	}
-
 	var result struct {
-		Err               *APIError          `json:"error"`
 		BankAuthorisation *BankAuthorisation `json:"bank_authorisations"`
 	}
 
-	err = try(o.retries, func() error {
-		res, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-
-		err = responseErr(res)
-		if err != nil {
-			return err
-		}
-
-		err = json.NewDecoder(res.Body).Decode(&result)
-		if err != nil {
-			return err
-		}
-
-		if result.Err != nil {
-			return result.Err
-		}
-
-		return nil
-	})
+	err := execute(ctx, s.config, &apiRequest{
+		service:  "bank_authorisations",
+		action:   "get",
+		method:   "GET",
+		path:     fmt.Sprintf("/bank_authorisations/%v", identity),
+		identity: identity,
+	}, &result, opts)
 	if err != nil {
 		return nil, err
 	}
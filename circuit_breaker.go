@@ -0,0 +1,207 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen matches the errors returned by requests a CircuitBreaker
+// stops from being sent
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests without sending them
+	CircuitOpen
+	// CircuitHalfOpen lets a single request through to probe whether the
+	// API has recovered
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerSettings configure a CircuitBreaker
+type CircuitBreakerSettings struct {
+	// Threshold is the number of consecutive server errors or timeouts
+	// which open the breaker, five if unset
+	Threshold int
+	// Cooldown is how long the breaker stays open before letting a request
+	// through to probe the API, thirty seconds if unset
+	Cooldown time.Duration
+	// OnStateChange is called whenever the breaker changes state
+	OnStateChange func(name string, from, to CircuitState)
+}
+
+// CircuitBreaker stops requests from being sent while the API is failing,
+// so that callers fail fast rather than piling up retries. It opens after a
+// run of consecutive server errors or timeouts, and is safe for concurrent
+// use.
+type CircuitBreaker struct {
+	name     string
+	settings CircuitBreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker, named for the state
+// change callback and errors
+func NewCircuitBreaker(name string, settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.Threshold <= 0 {
+		settings.Threshold = 5
+	}
+	if settings.Cooldown <= 0 {
+		settings.Cooldown = 30 * time.Second
+	}
+	return &CircuitBreaker{
+		name:     name,
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+// WithCircuitBreaker guards every request made with the config with breaker
+func WithCircuitBreaker(breaker *CircuitBreaker) ConfigOption {
+	return WithCircuitBreakers(breaker, breaker)
+}
+
+// WithCircuitBreakers guards reads and writes made with the config with
+// separate breakers, either of which may be nil
+func WithCircuitBreakers(reads, writes *CircuitBreaker) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.readBreaker = reads
+			c.writeBreaker = writes
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// WithServiceCircuitBreaker guards requests to one service, such as
+// "payments", with breaker instead of the config's other breakers
+func WithServiceCircuitBreaker(service string, breaker *CircuitBreaker) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			serviceBreakers := map[string]*CircuitBreaker{service: breaker}
+			for s, b := range c.serviceBreakers {
+				if s != service {
+					serviceBreakers[s] = b
+				}
+			}
+			c.serviceBreakers = serviceBreakers
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// circuitBreaker returns the breaker guarding r, if any
+func (c *config) circuitBreaker(r *apiRequest) *CircuitBreaker {
+	if b, ok := c.serviceBreakers[r.service]; ok {
+		return b
+	}
+	if r.mutating() {
+		return c.writeBreaker
+	}
+	return c.readBreaker
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a request may be sent, returning an error matching
+// ErrCircuitOpen if not
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	from := b.state
+	switch {
+	case b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.settings.Cooldown:
+		b.state = CircuitHalfOpen
+		b.probing = true
+	case b.state == CircuitHalfOpen && !b.probing:
+		b.probing = true
+	case b.state != CircuitClosed:
+		b.mu.Unlock()
+		return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return nil
+}
+
+// record updates the breaker with the outcome of a request it allowed
+func (b *CircuitBreaker) record(res *http.Response, err error) {
+	failed := breaksCircuit(res, err)
+
+	b.mu.Lock()
+	from := b.state
+	b.probing = false
+	switch {
+	case failed:
+		b.failures++
+		if b.state == CircuitHalfOpen || b.failures >= b.settings.Threshold {
+			b.state = CircuitOpen
+			b.openedAt = b.now()
+		}
+	case res != nil:
+		b.failures = 0
+		b.state = CircuitClosed
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+func (b *CircuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(b.name, from, to)
+	}
+}
+
+// breaksCircuit reports whether the outcome of a request counts towards
+// opening a breaker: a server error or a timeout. Other failures, such as
+// the request being canceled, say nothing about the health of the API.
+func breaksCircuit(res *http.Response, err error) bool {
+	if res != nil {
+		return res.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
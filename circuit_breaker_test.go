@@ -0,0 +1,202 @@
+package gocardless
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// outageServer fails every request with a server error while down is set
+func outageServer(down *atomic.Bool, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"message":"unavailable","type":"gocardless"}}`))
+			return
+		}
+		w.Write([]byte(`{"payments":{"id":"PM123"}}`))
+	}))
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var down atomic.Bool
+	var calls atomic.Int32
+	down.Store(true)
+	server := outageServer(&down, &calls)
+	defer server.Close()
+
+	var changes []string
+	breaker := NewCircuitBreaker("gocardless", CircuitBreakerSettings{
+		Threshold: 3,
+		Cooldown:  time.Minute,
+		OnStateChange: func(name string, from, to CircuitState) {
+			changes = append(changes, name+": "+from.String()+" -> "+to.String())
+		},
+	})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithCircuitBreaker(breaker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+	ctx := context.TODO()
+
+	// The default retry policy makes three attempts, opening the breaker
+	if _, err := client.Payments.Get(ctx, "PM123"); StatusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("expected a server error, got %v", err)
+	}
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("expected breaker to be open, got %s", breaker.State())
+	}
+
+	if _, err := client.Payments.Get(ctx, "PM123"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected requests to fail fast while open, got %d calls", calls.Load())
+	}
+
+	// A failed probe after the cooldown opens the breaker again
+	now = now.Add(time.Minute)
+	if _, err := client.Payments.Get(ctx, "PM123", WithoutRetries()); StatusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("expected the probe to be sent, got %v", err)
+	}
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("expected breaker to reopen, got %s", breaker.State())
+	}
+
+	// A successful probe closes it
+	down.Store(false)
+	now = now.Add(time.Minute)
+	if _, err := client.Payments.Get(ctx, "PM123"); err != nil {
+		t.Fatal(err)
+	}
+	if breaker.State() != CircuitClosed {
+		t.Fatalf("expected breaker to close, got %s", breaker.State())
+	}
+
+	expected := []string{
+		"gocardless: closed -> open",
+		"gocardless: open -> half-open",
+		"gocardless: half-open -> open",
+		"gocardless: open -> half-open",
+		"gocardless: half-open -> closed",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected state changes %v, got %v", expected, changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Fatalf("expected state changes %v, got %v", expected, changes)
+		}
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAllowsSingleProbe(t *testing.T) {
+	breaker := NewCircuitBreaker("gocardless", CircuitBreakerSettings{Threshold: 1, Cooldown: time.Millisecond})
+	breaker.record(&http.Response{StatusCode: http.StatusInternalServerError}, nil)
+	time.Sleep(2 * time.Millisecond)
+
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("expected a probe to be allowed, got %v", err)
+	}
+	if err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected other requests to fail while probing, got %v", err)
+	}
+	// A canceled probe says nothing about the API, so another may be sent
+	breaker.record(nil, context.Canceled)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("expected another probe to be allowed, got %v", err)
+	}
+}
+
+func TestCircuitBreakers_SeparateReadsAndWrites(t *testing.T) {
+	var down atomic.Bool
+	var calls atomic.Int32
+	down.Store(true)
+	server := outageServer(&down, &calls)
+	defer server.Close()
+
+	reads := NewCircuitBreaker("reads", CircuitBreakerSettings{Threshold: 1})
+	writes := NewCircuitBreaker("writes", CircuitBreakerSettings{Threshold: 1})
+	mandates := NewCircuitBreaker("mandates", CircuitBreakerSettings{Threshold: 1})
+	cfg, err := NewConfig("dummy_token",
+		WithEndpoint(server.URL),
+		WithCircuitBreakers(reads, writes),
+		WithServiceCircuitBreaker("mandates", mandates),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	client.Payments.Create(context.TODO(), PaymentCreateParams{Amount: 1000})
+	if writes.State() != CircuitOpen {
+		t.Fatal("expected the write breaker to open")
+	}
+	if reads.State() != CircuitClosed || mandates.State() != CircuitClosed {
+		t.Fatal("expected other breakers to stay closed")
+	}
+
+	client.Mandates.Get(context.TODO(), "MD123")
+	if mandates.State() != CircuitOpen || reads.State() != CircuitClosed {
+		t.Fatal("expected only the service's breaker to open")
+	}
+}
+
+func TestCircuitBreaker_ReportsFastFailures(t *testing.T) {
+	var down atomic.Bool
+	var calls atomic.Int32
+	down.Store(true)
+	server := outageServer(&down, &calls)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	metrics := NewMemoryMetrics()
+	breaker := NewCircuitBreaker("gocardless", CircuitBreakerSettings{Threshold: 1, Cooldown: time.Minute})
+	cfg, err := NewConfig("dummy_token",
+		WithEndpoint(server.URL),
+		WithCircuitBreaker(breaker),
+		WithMetrics(metrics),
+		WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	client.Payments.Get(context.TODO(), "PM123")
+	if _, err := client.Payments.Get(context.TODO(), "PM123"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// The first call's retry is rejected too, once the breaker has opened
+	requests := metrics.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("expected the failed and the rejected attempts to be recorded, got %+v", requests)
+	}
+	if requests[0].ErrorType != "gocardless" {
+		t.Fatalf("unexpected metric for the failed attempt %+v", requests[0])
+	}
+	for _, m := range requests[1:] {
+		if m.ErrorType != "circuit_open" || m.StatusCode != 0 {
+			t.Fatalf("unexpected metric for a rejected attempt %+v", m)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected rejected attempts not to be sent, got %d calls", calls.Load())
+	}
+	if strings.Count(buf.String(), "\n") != 3 || !strings.Contains(buf.String(), "circuit breaker is open") {
+		t.Fatalf("expected the rejected attempt to be logged, got %s", buf.String())
+	}
+}
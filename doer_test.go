@@ -0,0 +1,63 @@
+package gocardless
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fakeDoer answers requests in process without an HTTP stack
+type fakeDoer struct {
+	requests []*http.Request
+}
+
+func (d *fakeDoer) Do(req *http.Request) (*http.Response, error) {
+	d.requests = append(d.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"payments":{"id":"PM123"}}`)),
+	}, nil
+}
+
+func TestWithDoer(t *testing.T) {
+	doer := &fakeDoer{}
+	cfg, err := NewConfig("dummy_token", WithEndpoint("http://gocardless.invalid"), WithDoer(doer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	payment, err := client.Payments.Get(context.TODO(), "PM123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.Id != "PM123" {
+		t.Fatalf("unexpected payment %+v", payment)
+	}
+	if len(doer.requests) != 1 || doer.requests[0].URL.Path != "/payments/PM123" {
+		t.Fatalf("expected the request to be sent through the doer, got %v", doer.requests)
+	}
+	if doer.requests[0].Header.Get("Authorization") != "Bearer dummy_token" {
+		t.Fatal("expected the request to be authenticated")
+	}
+}
+
+func TestWithDoer_TakesPrecedenceOverClient(t *testing.T) {
+	var _ Doer = &http.Client{}
+
+	doer := &fakeDoer{}
+	cfg, err := NewConfig("dummy_token", WithDoer(doer), WithClient(&http.Client{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+	if _, err := client.Payments.Get(context.TODO(), "PM123"); err != nil {
+		t.Fatal(err)
+	}
+	if len(doer.requests) != 1 {
+		t.Fatal("expected the doer to be used")
+	}
+}
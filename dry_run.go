@@ -0,0 +1,101 @@
+package gocardless
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrNotExecuted is returned by mutating requests made with a dry run
+// config, which are recorded instead of being sent
+var ErrNotExecuted = errors.New("not executed in dry run")
+
+// PlannedRequest is a mutating request recorded by a DryRunRecorder
+type PlannedRequest struct {
+	Service        string          `json:"service"`
+	Action         string          `json:"action"`
+	Method         string          `json:"method"`
+	Path           string          `json:"path"`
+	Body           json.RawMessage `json:"body,omitempty"`
+	IdempotencyKey string          `json:"idempotency_key"`
+}
+
+// DryRunRecorder records the mutating requests made with a dry run config,
+// and is safe for concurrent use
+type DryRunRecorder struct {
+	mu       sync.Mutex
+	requests []PlannedRequest
+}
+
+// NewDryRunRecorder returns an empty DryRunRecorder
+func NewDryRunRecorder() *DryRunRecorder {
+	return &DryRunRecorder{}
+}
+
+// WithDryRun records every request which would change state on the API with
+// recorder instead of sending it, returning ErrNotExecuted. Reads are sent
+// as normal.
+func WithDryRun(recorder *DryRunRecorder) ConfigOption {
+	return func(cfg Config) error {
+		if recorder == nil {
+			return errors.New("dry run recorder required")
+		}
+		if c, ok := cfg.(*config); ok {
+			c.dryRun = recorder
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// Requests returns the requests recorded so far, in the order they were made
+func (d *DryRunRecorder) Requests() []PlannedRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]PlannedRequest(nil), d.requests...)
+}
+
+// WriteJSONLines writes the requests recorded so far to w as JSON Lines, one
+// request per line
+func (d *DryRunRecorder) WriteJSONLines(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, r := range d.Requests() {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record adds req to the plan in place of sending it
+func (d *DryRunRecorder) record(r *apiRequest, req *http.Request) error {
+	planned := PlannedRequest{
+		Service:        r.service,
+		Action:         r.action,
+		Method:         req.Method,
+		Path:           req.URL.RequestURI(),
+		IdempotencyKey: req.Header.Get("Idempotency-Key"),
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+		planned.Body = bytes.TrimSpace(data)
+	}
+
+	d.mu.Lock()
+	d.requests = append(d.requests, planned)
+	d.mu.Unlock()
+	return fmt.Errorf("%s %s: %w", planned.Method, planned.Path, ErrNotExecuted)
+}
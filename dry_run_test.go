@@ -0,0 +1,88 @@
+package gocardless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithDryRun(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"subscriptions":{"id":"SB123","amount":1000}}`))
+	}))
+	defer server.Close()
+
+	recorder := NewDryRunRecorder()
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithDryRun(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+	ctx := context.TODO()
+
+	if _, err := client.Subscriptions.Get(ctx, "SB123"); err != nil {
+		t.Fatal(err)
+	}
+
+	subscription, err := client.Subscriptions.Update(ctx, "SB123", SubscriptionUpdateParams{Amount: 1200}, WithIdempotencyKey("reprice-SB123"))
+	if !errors.Is(err, ErrNotExecuted) {
+		t.Fatalf("expected ErrNotExecuted, got %v", err)
+	}
+	if subscription != nil {
+		t.Fatal("expected no result from a dry run")
+	}
+	if _, err := client.Mandates.Cancel(ctx, "MD123", MandateCancelParams{}); !errors.Is(err, ErrNotExecuted) {
+		t.Fatalf("expected ErrNotExecuted, got %v", err)
+	}
+
+	if len(requests) != 1 || requests[0] != "GET /subscriptions/SB123" {
+		t.Fatalf("expected only reads to reach the API, got %v", requests)
+	}
+
+	planned := recorder.Requests()
+	if len(planned) != 2 {
+		t.Fatalf("expected two planned requests, got %d", len(planned))
+	}
+	update := planned[0]
+	if update.Method != "PUT" || update.Path != "/subscriptions/SB123" || update.Service != "subscriptions" || update.Action != "update" {
+		t.Fatalf("unexpected planned request %+v", update)
+	}
+	if update.IdempotencyKey != "reprice-SB123" {
+		t.Fatalf("expected the idempotency key to be recorded, got %q", update.IdempotencyKey)
+	}
+	if string(update.Body) != `{"subscriptions":{"amount":1200}}` {
+		t.Fatalf("unexpected body %s", update.Body)
+	}
+	if planned[1].Path != "/mandates/MD123/actions/cancel" || planned[1].IdempotencyKey == "" {
+		t.Fatalf("unexpected planned request %+v", planned[1])
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.WriteJSONLines(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a line per request, got %q", buf.String())
+	}
+	var decoded PlannedRequest
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Path != update.Path || string(decoded.Body) != string(update.Body) {
+		t.Fatalf("unexpected line %s", lines[0])
+	}
+}
+
+func TestWithDryRun_RequiresRecorder(t *testing.T) {
+	if _, err := NewConfig("dummy_token", WithDryRun(nil)); err == nil {
+		t.Fatal("expected a nil recorder to be rejected rather than making requests for real")
+	}
+}
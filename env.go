@@ -0,0 +1,125 @@
+package gocardless
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewConfigFromEnv
+const (
+	EnvAccessToken    = "GOCARDLESS_ACCESS_TOKEN"
+	EnvEnvironment    = "GOCARDLESS_ENVIRONMENT"
+	EnvEndpoint       = "GOCARDLESS_ENDPOINT"
+	EnvTimeout        = "GOCARDLESS_TIMEOUT"
+	EnvMaxAttempts    = "GOCARDLESS_MAX_ATTEMPTS"
+	EnvRetryBaseDelay = "GOCARDLESS_RETRY_BASE_DELAY"
+	EnvRetryMaxDelay  = "GOCARDLESS_RETRY_MAX_DELAY"
+)
+
+// environmentEndpoints maps the values of GOCARDLESS_ENVIRONMENT to the
+// endpoint of that environment
+var environmentEndpoints = map[string]string{
+	"live":    LiveEndpoint,
+	"sandbox": SandboxEndpoint,
+}
+
+// NewConfigFromEnv returns a config read from the environment:
+//
+//   - GOCARDLESS_ACCESS_TOKEN is the access token, and is required
+//   - GOCARDLESS_ENVIRONMENT is "live" or "sandbox", and may be omitted if
+//     the token is prefixed with its environment
+//   - GOCARDLESS_ENDPOINT overrides the endpoint of the environment
+//   - GOCARDLESS_TIMEOUT is the overall timeout of each request, e.g. "30s"
+//   - GOCARDLESS_MAX_ATTEMPTS, GOCARDLESS_RETRY_BASE_DELAY and
+//     GOCARDLESS_RETRY_MAX_DELAY configure the retry policy
+//
+// Tokens and endpoints belonging to a different environment than the one
+// configured are refused. Any options given are applied after those read
+// from the environment.
+func NewConfigFromEnv(configOpts ...ConfigOption) (Config, error) {
+	token := os.Getenv(EnvAccessToken)
+	if token == "" {
+		return nil, fmt.Errorf("%s required", EnvAccessToken)
+	}
+
+	tokenEnv := ""
+	for env := range environmentEndpoints {
+		if strings.HasPrefix(token, env+"_") {
+			tokenEnv = env
+		}
+	}
+
+	env := strings.ToLower(strings.TrimSpace(os.Getenv(EnvEnvironment)))
+	switch {
+	case env == "" && tokenEnv == "":
+		return nil, fmt.Errorf("%s required, as the environment of %s cannot be determined", EnvEnvironment, EnvAccessToken)
+	case env == "":
+		env = tokenEnv
+	case environmentEndpoints[env] == "":
+		return nil, fmt.Errorf("%s must be live or sandbox, got %q", EnvEnvironment, env)
+	case tokenEnv != "" && tokenEnv != env:
+		return nil, fmt.Errorf("%s is %s but %s is a %s token", EnvEnvironment, env, EnvAccessToken, tokenEnv)
+	}
+
+	opts := []ConfigOption{WithEndpoint(environmentEndpoints[env])}
+	if endpoint := os.Getenv(EnvEndpoint); endpoint != "" {
+		for other, otherEndpoint := range environmentEndpoints {
+			if other != env && strings.TrimSuffix(endpoint, "/") == otherEndpoint {
+				return nil, fmt.Errorf("%s is %s but %s is the %s endpoint", EnvEnvironment, env, EnvEndpoint, other)
+			}
+		}
+		opts = append(opts, WithEndpoint(endpoint))
+	}
+
+	if v := os.Getenv(EnvTimeout); v != "" {
+		timeout, err := envDuration(EnvTimeout, v)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithClientTimeout(timeout))
+	}
+
+	policy := DefaultRetryPolicy
+	retries := false
+	if v := os.Getenv(EnvMaxAttempts); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("%s must be a positive integer, got %q", EnvMaxAttempts, v)
+		}
+		policy.MaxAttempts = n
+		retries = true
+	}
+	if v := os.Getenv(EnvRetryBaseDelay); v != "" {
+		d, err := envDuration(EnvRetryBaseDelay, v)
+		if err != nil {
+			return nil, err
+		}
+		policy.BaseDelay = d
+		policy.Jitter = true
+		retries = true
+	}
+	if v := os.Getenv(EnvRetryMaxDelay); v != "" {
+		d, err := envDuration(EnvRetryMaxDelay, v)
+		if err != nil {
+			return nil, err
+		}
+		policy.MaxDelay = d
+		retries = true
+	}
+	if retries {
+		opts = append(opts, WithRetryPolicy(policy))
+	}
+
+	return NewConfig(token, append(opts, configOpts...)...)
+}
+
+func envDuration(name, v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s must be a duration such as \"30s\", got %q", name, v)
+	}
+	return d, nil
+}
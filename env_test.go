@@ -0,0 +1,88 @@
+package gocardless
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvAccessToken, "sandbox_abc")
+	t.Setenv(EnvEnvironment, "sandbox")
+	t.Setenv(EnvTimeout, "30s")
+	t.Setenv(EnvMaxAttempts, "5")
+	t.Setenv(EnvRetryBaseDelay, "100ms")
+
+	cfg, err := NewConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Token() != "sandbox_abc" {
+		t.Fatalf("unexpected token %q", cfg.Token())
+	}
+	if cfg.Endpoint() != SandboxEndpoint {
+		t.Fatalf("expected sandbox endpoint, got %s", cfg.Endpoint())
+	}
+	if cfg.Client() == nil || cfg.Client().Timeout != 30*time.Second {
+		t.Fatal("expected timeout to be configured")
+	}
+	policy := cfg.(*config).retryPolicy
+	if policy == nil || policy.MaxAttempts != 5 || policy.BaseDelay != 100*time.Millisecond {
+		t.Fatalf("unexpected retry policy %+v", policy)
+	}
+}
+
+func TestNewConfigFromEnv_EnvironmentFromToken(t *testing.T) {
+	t.Setenv(EnvAccessToken, "live_abc")
+	t.Setenv(EnvEnvironment, "")
+
+	cfg, err := NewConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint() != LiveEndpoint {
+		t.Fatalf("expected live endpoint, got %s", cfg.Endpoint())
+	}
+}
+
+func TestNewConfigFromEnv_EndpointOverride(t *testing.T) {
+	t.Setenv(EnvAccessToken, "sandbox_abc")
+	t.Setenv(EnvEnvironment, "sandbox")
+	t.Setenv(EnvEndpoint, "http://localhost:8080")
+
+	cfg, err := NewConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint() != "http://localhost:8080" {
+		t.Fatalf("expected endpoint override, got %s", cfg.Endpoint())
+	}
+}
+
+func TestNewConfigFromEnv_Errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		env      map[string]string
+		contains string
+	}{
+		{"missing token", map[string]string{}, "GOCARDLESS_ACCESS_TOKEN required"},
+		{"unknown environment", map[string]string{EnvAccessToken: "abc"}, "GOCARDLESS_ENVIRONMENT required"},
+		{"invalid environment", map[string]string{EnvAccessToken: "abc", EnvEnvironment: "staging"}, "must be live or sandbox"},
+		{"live token in sandbox", map[string]string{EnvAccessToken: "live_abc", EnvEnvironment: "sandbox"}, "is a live token"},
+		{"sandbox token in live", map[string]string{EnvAccessToken: "sandbox_abc", EnvEnvironment: "live"}, "is a sandbox token"},
+		{"sandbox endpoint in live", map[string]string{EnvAccessToken: "live_abc", EnvEndpoint: SandboxEndpoint}, "is the sandbox endpoint"},
+		{"invalid timeout", map[string]string{EnvAccessToken: "live_abc", EnvTimeout: "soon"}, "GOCARDLESS_TIMEOUT must be a duration"},
+		{"invalid attempts", map[string]string{EnvAccessToken: "live_abc", EnvMaxAttempts: "0"}, "GOCARDLESS_MAX_ATTEMPTS must be a positive integer"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range []string{EnvAccessToken, EnvEnvironment, EnvEndpoint, EnvTimeout, EnvMaxAttempts, EnvRetryBaseDelay, EnvRetryMaxDelay} {
+				t.Setenv(name, tc.env[name])
+			}
+			_, err := NewConfigFromEnv()
+			if err == nil || !strings.Contains(err.Error(), tc.contains) {
+				t.Fatalf("expected error containing %q, got %v", tc.contains, err)
+			}
+		})
+	}
+}
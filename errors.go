@@ -0,0 +1,126 @@
+package gocardless
+
+import (
+	"errors"
+	"net/http"
+)
+
+// apiErrorKind matches API errors of a given type or reason with errors.Is
+type apiErrorKind struct {
+	errType string
+	reason  string
+}
+
+func (k *apiErrorKind) Error() string {
+	if k.reason != "" {
+		return k.reason
+	}
+	return k.errType
+}
+
+// Error types returned by the API. An error returned by any of the services
+// can be matched against these with errors.Is.
+var (
+	ErrGoCardless       error = &apiErrorKind{errType: "gocardless"}
+	ErrInvalidAPIUsage  error = &apiErrorKind{errType: "invalid_api_usage"}
+	ErrInvalidState     error = &apiErrorKind{errType: "invalid_state"}
+	ErrValidationFailed error = &apiErrorKind{errType: "validation_failed"}
+)
+
+// Common reasons given by the API for an error, which can be matched
+// against with errors.Is
+var (
+	ErrIdempotentCreationConflict error = &apiErrorKind{reason: "idempotent_creation_conflict"}
+	ErrMandateIsInactive          error = &apiErrorKind{reason: "mandate_is_inactive"}
+	ErrRateLimitExceeded          error = &apiErrorKind{reason: "rate_limit_exceeded"}
+	ErrResourceNotFound           error = &apiErrorKind{reason: "resource_not_found"}
+	ErrAccessTokenNotFound        error = &apiErrorKind{reason: "access_token_not_found"}
+	ErrInsufficientPermissions    error = &apiErrorKind{reason: "insufficient_permissions"}
+	ErrCancellationFailed         error = &apiErrorKind{reason: "cancellation_failed"}
+	ErrRetryFailed                error = &apiErrorKind{reason: "retry_failed"}
+	ErrBankAccountExists          error = &apiErrorKind{reason: "bank_account_exists"}
+)
+
+// Is reports whether err is of the type or has the reason given by one of
+// the sentinel errors above
+func (err *APIError) Is(target error) bool {
+	k, ok := target.(*apiErrorKind)
+	if !ok {
+		return false
+	}
+	if k.errType != "" {
+		return err.Type == k.errType
+	}
+	return err.HasReason(k.reason)
+}
+
+// HasReason reports whether any of the errors the API gave has the reason
+func (err *APIError) HasReason(reason string) bool {
+	for _, e := range err.Errors {
+		if e.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusCode returns the HTTP status code of the API response which caused
+// err, or zero if err was not caused by a response from the API
+func StatusCode(err error) int {
+	var re *responseError
+	if errors.As(err, &re) {
+		return re.res.StatusCode
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
+
+// RequestID returns the ID the API assigned to the request which caused
+// err, for use when contacting support, or an empty string if there is none
+func RequestID(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+		return apiErr.RequestID
+	}
+	var re *responseError
+	if errors.As(err, &re) && re.res.Header != nil {
+		return re.res.Header.Get("X-Request-Id")
+	}
+	return ""
+}
+
+// ConflictingResourceID returns the ID of the existing resource err
+// conflicts with, such as the resource originally created with the same
+// idempotency key, or an empty string if there is none
+func ConflictingResourceID(err error) string {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	for _, e := range apiErr.Errors {
+		if e.Links.ConflictingResourceID != "" {
+			return e.Links.ConflictingResourceID
+		}
+	}
+	return ""
+}
+
+// IsRateLimited reports whether err was caused by the request being rate
+// limited by the API
+func IsRateLimited(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests || errors.Is(err, ErrRateLimitExceeded)
+}
+
+// IsNotFound reports whether err was caused by a resource not existing
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound || errors.Is(err, ErrResourceNotFound)
+}
+
+// IsConflict reports whether err was caused by the request conflicting with
+// an existing resource, such as an idempotent creation conflict
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict || errors.Is(err, ErrIdempotentCreationConflict)
+}
@@ -0,0 +1,105 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func errorResponse(status int, body string) error {
+	return responseErr(&http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"X-Request-Id": []string{"header-request-id"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	})
+}
+
+func TestAPIError_IsMatchesTypeAndReason(t *testing.T) {
+	err := errorResponse(http.StatusUnprocessableEntity, `{"error":{"message":"Mandate is inactive","type":"invalid_state","request_id":"RQ123","code":422,"errors":[{"reason":"mandate_is_inactive","message":"Mandate is inactive"}]}}`)
+
+	if !errors.Is(err, ErrInvalidState) {
+		t.Error("expected error to match ErrInvalidState")
+	}
+	if !errors.Is(err, ErrMandateIsInactive) {
+		t.Error("expected error to match ErrMandateIsInactive")
+	}
+	if errors.Is(err, ErrValidationFailed) {
+		t.Error("expected error not to match ErrValidationFailed")
+	}
+	if errors.Is(err, ErrIdempotentCreationConflict) {
+		t.Error("expected error not to match ErrIdempotentCreationConflict")
+	}
+
+	wrapped := fmt.Errorf("creating payment: %w", err)
+	if !errors.Is(wrapped, ErrMandateIsInactive) {
+		t.Error("expected wrapped error to match ErrMandateIsInactive")
+	}
+}
+
+func TestStatusCodeAndRequestID(t *testing.T) {
+	err := errorResponse(http.StatusConflict, `{"error":{"message":"conflict","type":"invalid_state","request_id":"RQ123","code":409,"errors":[{"reason":"idempotent_creation_conflict"}]}}`)
+	if got := StatusCode(err); got != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", got)
+	}
+	if got := RequestID(err); got != "RQ123" {
+		t.Errorf("expected request ID from the API error, got %q", got)
+	}
+	if !IsConflict(err) {
+		t.Error("expected IsConflict to be true")
+	}
+
+	err = errorResponse(http.StatusBadGateway, `<html>Bad Gateway</html>`)
+	if got := StatusCode(err); got != http.StatusBadGateway {
+		t.Errorf("expected status of undecodable response to be kept, got %d", got)
+	}
+	if got := RequestID(err); got != "header-request-id" {
+		t.Errorf("expected request ID from the response headers, got %q", got)
+	}
+
+	if got := StatusCode(errors.New("boom")); got != 0 {
+		t.Errorf("expected zero status for other errors, got %d", got)
+	}
+}
+
+func TestErrorHelpers(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		is   func(error) bool
+		want bool
+	}{
+		{"rate limited status", errorResponse(http.StatusTooManyRequests, `{"error":"slow down"}`), IsRateLimited, true},
+		{"not found status", errorResponse(http.StatusNotFound, `{"error":{"type":"invalid_api_usage","errors":[{"reason":"resource_not_found"}]}}`), IsNotFound, true},
+		{"not found reason", &APIError{Type: "invalid_api_usage", Errors: []ValidationError{{Reason: "resource_not_found"}}}, IsNotFound, true},
+		{"server error", errorResponse(http.StatusInternalServerError, `{"error":"oops"}`), IsNotFound, false},
+		{"other error", errors.New("boom"), IsRateLimited, false},
+	}
+	for _, tt := range tests {
+		if got := tt.is(tt.err); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServiceErrorsMatchSentinels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		io.WriteString(w, `{"error":{"message":"Validation failed","type":"validation_failed","request_id":"RQ123","code":422,"errors":[{"field":"amount","reason":"bank_account_exists"}]}}`)
+	}))
+	defer server.Close()
+
+	client, _ := getClient(t, server.URL)
+	_, err := client.CustomerBankAccounts.Create(context.TODO(), CustomerBankAccountCreateParams{})
+	if !errors.Is(err, ErrValidationFailed) || !errors.Is(err, ErrBankAccountExists) {
+		t.Fatalf("expected validation error with reason, got %v", err)
+	}
+	if StatusCode(err) != http.StatusUnprocessableEntity || RequestID(err) != "RQ123" {
+		t.Fatalf("expected status and request ID, got %d %q", StatusCode(err), RequestID(err))
+	}
+}
@@ -7,6 +7,8 @@ import (
 )
 
 type Service struct {
+	config Config
+
 	Balances                       BalanceService
 	BankAccountDetails             BankAccountDetailService
 	BankAccountHolderVerifications BankAccountHolderVerificationService
@@ -65,6 +67,7 @@ func New(config Config) (*Service, error) {
 	}
 
 	s := &Service{
+		config: config,
 		Balances: &BalanceServiceImpl{
 			config: config,
 		}, BankAccountDetails: &BankAccountDetailServiceImpl{
@@ -163,6 +166,27 @@ func New(config Config) (*Service, error) {
 	return s, nil
 }
 
+// ForToken returns a client which makes requests with the given access
+// token, sharing this client's configuration such as its HTTP client and
+// middleware. As the API limits requests per access token, the new client
+// is throttled by the rate limiter kept for its token if this one is rate
+// limited, which every client derived for that token shares.
+func (s *Service) ForToken(token string) (*Service, error) {
+	if token == "" {
+		return nil, errors.New("token required")
+	}
+	c, ok := s.config.(*config)
+	if !ok {
+		return nil, errors.New("invalid configuration")
+	}
+
+	derived := *c
+	derived.token = token
+	derived.tokenSource = nil
+	derived.rateLimiter = c.limiterFor(token)
+	return New(&derived)
+}
+
 type APIError struct {
 	Message          string            `json:"message"`
 	DocumentationUrl string            `json:"documentation_url"`
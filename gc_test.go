@@ -0,0 +1,100 @@
+package gocardless
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServiceForToken(t *testing.T) {
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"customers":{}}`))
+	}))
+	defer server.Close()
+
+	middlewareCalls := 0
+	mw := func(op Operation, req *http.Request, next RequestHandler) (*http.Response, error) {
+		middlewareCalls++
+		return next(req)
+	}
+	limiter := NewRateLimiter(time.Minute)
+	cfg, err := NewConfig("partner_token", WithEndpoint(server.URL), WithMiddleware(mw), WithRateLimiter(limiter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	merchant, err := client.ForToken("merchant_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := merchant.Customers.Get(context.TODO(), "CU123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Customers.Get(context.TODO(), "CU123"); err != nil {
+		t.Fatal(err)
+	}
+
+	if tokens[0] != "Bearer merchant_token" || tokens[1] != "Bearer partner_token" {
+		t.Fatalf("expected each client to use its own token, got %v", tokens)
+	}
+	if middlewareCalls != 2 {
+		t.Fatalf("expected middleware to be shared, got %d calls", middlewareCalls)
+	}
+
+	derived := merchant.config.(*config)
+	if derived.rateLimiter == nil || derived.rateLimiter == limiter {
+		t.Fatal("expected derived client to have a rate limiter for its token")
+	}
+	again, err := client.ForToken("merchant_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.config.(*config).rateLimiter != derived.rateLimiter {
+		t.Fatal("expected clients for the same token to share a rate limiter")
+	}
+	partner, err := merchant.ForToken("partner_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partner.config.(*config).rateLimiter != limiter {
+		t.Fatal("expected a client for the original token to share its rate limiter")
+	}
+
+	if _, err := client.ForToken(""); err == nil {
+		t.Fatal("expected error for empty token")
+	}
+}
+
+func TestServiceForToken_SharesRateLimiterWithAccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Limit", "1000")
+		w.Header().Set("RateLimit-Remaining", "500")
+		w.Write([]byte(`{"customers":{}}`))
+	}))
+	defer server.Close()
+
+	cfg, err := NewConfig("partner_token", WithEndpoint(server.URL), WithRateLimiter(NewRateLimiter(time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+	if _, err := client.Customers.Get(context.TODO(), "CU123", WithAccessToken("merchant_token")); err != nil {
+		t.Fatal(err)
+	}
+
+	merchant, err := client.ForToken("merchant_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := merchant.config.(*config).rateLimiter.State(); state.Limit != 1000 || state.Remaining > 500 {
+		t.Fatalf("expected the limit learned for the token to be shared, got %+v", state)
+	}
+	if state := cfg.(*config).rateLimiter.State(); state.Limit != 0 {
+		t.Fatalf("expected the client's own limiter to be unaffected, got %+v", state)
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/gocardless/gocardless-pro-go/v6
 
-go 1.20
+go 1.21
 
 require github.com/google/go-querystring v1.2.0
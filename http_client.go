@@ -0,0 +1,112 @@
+package gocardless
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Defaults for the HTTP client built when one is not configured with
+// WithClient
+const (
+	DefaultClientTimeout       = 60 * time.Second
+	DefaultConnectTimeout      = 10 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// transportSettings configure the HTTP client built for a config
+type transportSettings struct {
+	timeout             time.Duration
+	connectTimeout      time.Duration
+	tlsHandshakeTimeout time.Duration
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	disableHTTP2        bool
+}
+
+var defaultTransportSettings = transportSettings{
+	timeout:             DefaultClientTimeout,
+	connectTimeout:      DefaultConnectTimeout,
+	tlsHandshakeTimeout: DefaultTLSHandshakeTimeout,
+	maxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+	idleConnTimeout:     DefaultIdleConnTimeout,
+}
+
+// newHTTPClient builds the client used when the config has none, which
+// unlike http.DefaultClient never waits forever on a hung connection
+func newHTTPClient(s transportSettings) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   s.connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   s.tlsHandshakeTimeout,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   s.maxIdleConnsPerHost,
+		IdleConnTimeout:       s.idleConnTimeout,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     !s.disableHTTP2,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   s.timeout,
+	}
+}
+
+// transportOption returns a ConfigOption changing the settings of the
+// client built for the config. They have no effect if WithClient is used.
+func transportOption(fn func(*transportSettings)) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			fn(&c.transport)
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// WithClientTimeout sets the overall timeout of each attempt at a request,
+// including reading the response body. Zero means no timeout.
+func WithClientTimeout(d time.Duration) ConfigOption {
+	return transportOption(func(s *transportSettings) {
+		s.timeout = d
+	})
+}
+
+// WithConnectTimeout sets how long to wait for a connection to be
+// established
+func WithConnectTimeout(d time.Duration) ConfigOption {
+	return transportOption(func(s *transportSettings) {
+		s.connectTimeout = d
+	})
+}
+
+// WithTLSHandshakeTimeout sets how long to wait for the TLS handshake
+func WithTLSHandshakeTimeout(d time.Duration) ConfigOption {
+	return transportOption(func(s *transportSettings) {
+		s.tlsHandshakeTimeout = d
+	})
+}
+
+// WithIdleConns sets how many idle connections to the API are kept open for
+// reuse, and for how long
+func WithIdleConns(maxPerHost int, timeout time.Duration) ConfigOption {
+	return transportOption(func(s *transportSettings) {
+		s.maxIdleConnsPerHost = maxPerHost
+		s.idleConnTimeout = timeout
+	})
+}
+
+// WithHTTP2 sets whether HTTP/2 is used when the API supports it, which it
+// is by default
+func WithHTTP2(enabled bool) ConfigOption {
+	return transportOption(func(s *transportSettings) {
+		s.disableHTTP2 = !enabled
+	})
+}
@@ -0,0 +1,88 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewConfig_DefaultClient(t *testing.T) {
+	cfg, err := NewConfig("dummy_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := cfg.Client()
+	if client == nil || client == http.DefaultClient {
+		t.Fatal("expected a client to be built for the config")
+	}
+	if client.Timeout != DefaultClientTimeout {
+		t.Fatalf("expected default timeout, got %v", client.Timeout)
+	}
+	transport := client.Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != DefaultTLSHandshakeTimeout || !transport.ForceAttemptHTTP2 {
+		t.Fatalf("unexpected transport settings %+v", transport)
+	}
+}
+
+func TestNewConfig_TransportOptions(t *testing.T) {
+	cfg, err := NewConfig("dummy_token",
+		WithClientTimeout(5*time.Second),
+		WithTLSHandshakeTimeout(time.Second),
+		WithIdleConns(50, time.Minute),
+		WithHTTP2(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := cfg.Client()
+	if client.Timeout != 5*time.Second {
+		t.Fatalf("expected configured timeout, got %v", client.Timeout)
+	}
+	transport := client.Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != time.Second || transport.MaxIdleConnsPerHost != 50 ||
+		transport.IdleConnTimeout != time.Minute || transport.ForceAttemptHTTP2 {
+		t.Fatalf("unexpected transport settings %+v", transport)
+	}
+
+	custom := &http.Client{}
+	cfg, err = NewConfig("dummy_token", WithClient(custom), WithClientTimeout(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Client() != custom || custom.Timeout != 0 {
+		t.Fatal("expected a client given with WithClient to be used unchanged")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.Write([]byte(`{"payments":{"id":"PM123"}}`))
+	}))
+	defer server.Close()
+
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	start := time.Now()
+	_, err = client.Payments.Get(context.Background(), "PM123", WithTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the request to be abandoned at its deadline, took %v", elapsed)
+	}
+
+	if _, err := client.Payments.Get(context.Background(), "PM123", WithTimeout(0)); err == nil {
+		t.Fatal("expected a non-positive timeout to be rejected")
+	}
+}
@@ -0,0 +1,197 @@
+package gocardless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WithLogger logs every attempt at a request to logger. Attempts are logged
+// at info level, or warn level if they fail. With debug level enabled, the
+// request headers and the request and response bodies are also logged, with
+// the Authorization header redacted and bank account numbers and IBANs
+// masked.
+func WithLogger(logger *slog.Logger) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.logger = logger
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// logAttempt logs a single attempt at r, sent as req. res is nil if no
+// response was received, and body is the response body if it was captured.
+func logAttempt(ctx context.Context, logger *slog.Logger, r *apiRequest, req *http.Request, res *http.Response, body []byte, attempt int, latency time.Duration, retryReason string, err error) {
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("service", r.service),
+		slog.String("action", r.action),
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+	}
+	requestID := RequestID(err)
+	if res != nil {
+		attrs = append(attrs, slog.Int("status", res.StatusCode))
+		if requestID == "" {
+			requestID = res.Header.Get("X-Request-Id")
+		}
+	}
+	if requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	attrs = append(attrs,
+		slog.Int("attempt", attempt),
+		slog.Duration("latency", latency),
+	)
+	if retryReason != "" {
+		attrs = append(attrs, slog.String("retry_reason", retryReason))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs, slog.Any("request_headers", redactHeaders(req.Header)))
+		if req.GetBody != nil {
+			if rc, err := req.GetBody(); err == nil {
+				data, _ := io.ReadAll(rc)
+				rc.Close()
+				attrs = append(attrs, slog.String("request_body", maskBody(data)))
+			}
+		}
+		if body != nil {
+			attrs = append(attrs, slog.String("response_body", maskBody(body)))
+		}
+	}
+
+	logger.LogAttrs(ctx, level, "gocardless request", attrs...)
+}
+
+// captureBody reads the body of res so that it can be logged, replacing it
+// with a copy for the response to be decoded from
+func captureBody(res *http.Response) []byte {
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{err}))
+	return data
+}
+
+// errReader returns err once the captured part of a body has been read, so
+// that a failure reading it is still seen by the decoder
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+// retryReason describes why an attempt failed, for the log of the attempt
+// retrying it
+func retryReason(err error) string {
+	var re *responseError
+	var te *transportError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &re) && re.res.StatusCode == http.StatusTooManyRequests:
+		return "rate limited"
+	case errors.As(err, &re):
+		return fmt.Sprintf("status %d", re.res.StatusCode)
+	case errors.As(err, &te):
+		return "transport error: " + te.Error()
+	default:
+		return err.Error()
+	}
+}
+
+// redactHeaders returns the headers as a log value, with the access token
+// removed
+func redactHeaders(h http.Header) slog.Value {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		value := strings.Join(h.Values(key), ", ")
+		if key == "Authorization" {
+			value = "REDACTED"
+		}
+		attrs = append(attrs, slog.String(key, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// maskBody returns a JSON body for logging, with bank account numbers and
+// IBANs masked
+func maskBody(data []byte) string {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		// Without parsing the body the fields to mask can't be found
+		return fmt.Sprintf("<%d byte non-JSON body>", len(data))
+	}
+	masked, err := json.Marshal(maskValue("", v))
+	if err != nil {
+		return fmt.Sprintf("<%d byte body>", len(data))
+	}
+	return string(masked)
+}
+
+func maskValue(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			v[k] = maskValue(k, field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskValue(key, item)
+		}
+		return v
+	case string:
+		if key == "iban" || strings.HasSuffix(key, "account_number") {
+			return mask(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// mask replaces all but the last two characters of s
+func mask(s string) string {
+	if len(s) <= 2 {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-2) + s[len(s)-2:]
+}
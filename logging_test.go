@@ -0,0 +1,111 @@
+package gocardless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWithLogger_RecordPerAttempt(t *testing.T) {
+	var bodies []string
+	server := flakyServer(t, 1, `{"payments":{"id":"PM123"}}`, &bodies)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	cfg, err := NewConfig("secret_token", WithEndpoint(server.URL), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	if _, err := client.Payments.Get(context.TODO(), "PM123"); err != nil {
+		t.Fatal(err)
+	}
+
+	records := logRecords(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("expected a record per attempt, got %d", len(records))
+	}
+	first, second := records[0], records[1]
+	if first["level"] != "WARN" || first["status"] != float64(503) || first["attempt"] != float64(1) {
+		t.Fatalf("unexpected record for failed attempt %v", first)
+	}
+	if second["level"] != "INFO" || second["status"] != float64(200) || second["attempt"] != float64(2) {
+		t.Fatalf("unexpected record for successful attempt %v", second)
+	}
+	if second["service"] != "payments" || second["action"] != "get" || second["method"] != "GET" || second["path"] != "/payments/PM123" {
+		t.Fatalf("expected the request to be described, got %v", second)
+	}
+	if second["retry_reason"] != "status 503" {
+		t.Fatalf("expected the retry reason to be logged, got %v", second["retry_reason"])
+	}
+	if _, ok := second["latency"]; !ok {
+		t.Fatal("expected latency to be logged")
+	}
+	if strings.Contains(buf.String(), "secret_token") {
+		t.Fatal("expected the access token not to be logged")
+	}
+}
+
+func TestWithLogger_DebugBodiesMasked(t *testing.T) {
+	var bodies []string
+	server := flakyServer(t, 0, `{"customer_bank_accounts":{"id":"BA123","account_number_ending":"78","iban":"GB60BARC20000055779911"}}`, &bodies)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg, err := NewConfig("secret_token", WithEndpoint(server.URL), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	account, err := client.CustomerBankAccounts.Create(context.TODO(), CustomerBankAccountCreateParams{
+		AccountNumber: "55779911",
+		BranchCode:    "200000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Id != "BA123" {
+		t.Fatal("expected the response to be decoded after being logged")
+	}
+
+	records := logRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("expected one record, got %d", len(records))
+	}
+	record := records[0]
+	headers := record["request_headers"].(map[string]interface{})
+	if headers["Authorization"] != "REDACTED" {
+		t.Fatalf("expected the Authorization header to be redacted, got %v", headers["Authorization"])
+	}
+	if !strings.Contains(record["request_body"].(string), `"account_number":"******11"`) {
+		t.Fatalf("expected the account number to be masked, got %s", record["request_body"])
+	}
+	if !strings.Contains(record["response_body"].(string), `"iban":"********************11"`) {
+		t.Fatalf("expected the IBAN to be masked, got %s", record["response_body"])
+	}
+	for _, secret := range []string{"secret_token", "55779911", "GB60BARC20000055779911"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Fatalf("expected %q not to be logged", secret)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RequestMetric describes a single attempt at a request
+type RequestMetric struct {
+	Service string
+	Action  string
+	// StatusCode is zero if no response was received
+	StatusCode int
+	// ErrorType is empty if the attempt succeeded, the type of error given
+	// by the API such as "invalid_api_usage", or one of "http_error",
+	// "timeout", "canceled", "transport", "circuit_open" or "other"
+	ErrorType string
+	// Attempt is the number of the attempt, starting from one, so any
+	// greater number is a retry
+	Attempt  int
+	Duration time.Duration
+}
+
+// MetricsRecorder is told about every attempt at a request, and the rate
+// limit reported by each response. Implementations must be safe for
+// concurrent use.
+type MetricsRecorder interface {
+	RecordRequest(m RequestMetric)
+	RecordRateLimit(state RateLimitState)
+}
+
+// WithMetrics reports metrics for every request made with the config to
+// recorder
+func WithMetrics(recorder MetricsRecorder) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.metrics = recorder
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// recordMetrics reports an attempt at r to recorder
+func recordMetrics(recorder MetricsRecorder, r *apiRequest, res *http.Response, attempt int, duration time.Duration, err error) {
+	m := RequestMetric{
+		Service:   r.service,
+		Action:    r.action,
+		ErrorType: errorType(err),
+		Attempt:   attempt,
+		Duration:  duration,
+	}
+	if res != nil {
+		m.StatusCode = res.StatusCode
+	}
+	recorder.RecordRequest(m)
+
+	if res == nil {
+		return
+	}
+	limit, err := strconv.Atoi(res.Header.Get("RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(res.Header.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	state := RateLimitState{Limit: limit, Remaining: remaining}
+	if reset, err := parseRateLimitReset(res.Header.Get("RateLimit-Reset")); err == nil {
+		state.Reset = reset
+	}
+	recorder.RecordRateLimit(state)
+}
+
+// errorType classifies err for metrics, keeping the number of distinct
+// values small
+func errorType(err error) string {
+	var apiErr *APIError
+	var re *responseError
+	var te *transportError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &apiErr) && apiErr.Type != "":
+		return apiErr.Type
+	case errors.As(err, &re):
+		return "http_error"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &te):
+		var netErr net.Error
+		if errors.As(te.err, &netErr) && netErr.Timeout() {
+			return "timeout"
+		}
+		return "transport"
+	default:
+		return "other"
+	}
+}
+
+// MemoryMetrics is a MetricsRecorder keeping everything it is told in
+// memory, intended for tests
+type MemoryMetrics struct {
+	mu        sync.Mutex
+	requests  []RequestMetric
+	rateLimit RateLimitState
+}
+
+// NewMemoryMetrics returns an empty MemoryMetrics
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{}
+}
+
+func (m *MemoryMetrics) RecordRequest(metric RequestMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, metric)
+}
+
+func (m *MemoryMetrics) RecordRateLimit(state RateLimitState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimit = state
+}
+
+// Requests returns the attempts recorded so far, in the order they were made
+func (m *MemoryMetrics) Requests() []RequestMetric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RequestMetric(nil), m.requests...)
+}
+
+// RateLimit returns the rate limit most recently reported
+func (m *MemoryMetrics) RateLimit() RateLimitState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rateLimit
+}
@@ -0,0 +1,118 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMetrics(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("RateLimit-Limit", "1000")
+		w.Header().Set("RateLimit-Remaining", "997")
+		w.Header().Set("RateLimit-Reset", "Sat, 17 Oct 2026 12:00:00 GMT")
+		switch {
+		case r.URL.Path == "/payments/PM404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"not found","type":"invalid_api_usage","code":404,"errors":[{"reason":"resource_not_found"}]}}`))
+		case calls == 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"rate limited","type":"invalid_api_usage","code":429,"errors":[{"reason":"rate_limit_exceeded"}]}}`))
+		default:
+			w.Write([]byte(`{"payments":{"id":"PM123"}}`))
+		}
+	}))
+	defer server.Close()
+
+	metrics := NewMemoryMetrics()
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	if _, err := client.Payments.Get(context.TODO(), "PM123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Payments.Get(context.TODO(), "PM404"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	requests := metrics.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("expected a metric per attempt, got %d", len(requests))
+	}
+	expected := []RequestMetric{
+		{Service: "payments", Action: "get", StatusCode: 429, ErrorType: "invalid_api_usage", Attempt: 1},
+		{Service: "payments", Action: "get", StatusCode: 200, Attempt: 2},
+		{Service: "payments", Action: "get", StatusCode: 404, ErrorType: "invalid_api_usage", Attempt: 1},
+	}
+	for i, m := range requests {
+		if m.Duration <= 0 {
+			t.Errorf("expected duration to be recorded for attempt %d", i)
+		}
+		m.Duration = 0
+		if m != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], m)
+		}
+	}
+
+	state := metrics.RateLimit()
+	if state.Limit != 1000 || state.Remaining != 997 || state.Reset.IsZero() {
+		t.Fatalf("unexpected rate limit %+v", state)
+	}
+}
+
+func TestErrorType_Transport(t *testing.T) {
+	if got := errorType(&transportError{err: context.DeadlineExceeded}); got != "timeout" {
+		t.Fatalf("expected timeout, got %q", got)
+	}
+	if got := errorType(&transportError{err: errors.New("connection reset")}); got != "transport" {
+		t.Fatalf("expected transport, got %q", got)
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	p := NewPrometheusMetrics(0.1, 1)
+	p.RecordRequest(RequestMetric{Service: "payments", Action: "create", StatusCode: 503, ErrorType: "gocardless", Attempt: 1, Duration: 50 * time.Millisecond})
+	p.RecordRequest(RequestMetric{Service: "payments", Action: "create", StatusCode: 201, Attempt: 2, Duration: 500 * time.Millisecond})
+	p.RecordRateLimit(RateLimitState{Limit: 1000, Remaining: 998})
+
+	server := httptest.NewServer(p)
+	defer server.Close()
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", res.Header.Get("Content-Type"))
+	}
+
+	var out strings.Builder
+	if _, err := p.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`gocardless_requests_total{service="payments",action="create",status="201",error_type=""} 1`,
+		`gocardless_requests_total{service="payments",action="create",status="503",error_type="gocardless"} 1`,
+		`gocardless_retries_total{service="payments",action="create"} 1`,
+		`gocardless_request_duration_seconds_bucket{service="payments",action="create",le="0.1"} 1`,
+		`gocardless_request_duration_seconds_bucket{service="payments",action="create",le="1"} 2`,
+		`gocardless_request_duration_seconds_bucket{service="payments",action="create",le="+Inf"} 2`,
+		`gocardless_request_duration_seconds_sum{service="payments",action="create"} 0.55`,
+		`gocardless_request_duration_seconds_count{service="payments",action="create"} 2`,
+		`gocardless_rate_limit_limit 1000`,
+		`gocardless_rate_limit_remaining 998`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("expected output to contain %s, got:\n%s", line, out.String())
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package gocardless
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Operation describes the API call a request is being made for
+type Operation struct {
+	// Service is the resource collection being called, e.g. "payments"
+	Service string
+	// Action is the operation performed on it, e.g. "create" or "cancel"
+	Action string
+	// Identity is the ID of the resource acted on, if any
+	Identity string
+}
+
+// RequestHandler sends a request to the API and returns its response
+type RequestHandler func(req *http.Request) (*http.Response, error)
+
+// Middleware intercepts each request made by the services. It must call
+// next to send the request on, and may inspect or modify the request before
+// doing so and the response afterwards. Middleware runs once per attempt,
+// so a request that is retried passes through it again.
+type Middleware func(op Operation, req *http.Request, next RequestHandler) (*http.Response, error)
+
+// WithMiddleware registers middleware to run around every API request.
+// Middleware runs in the order given, the first being the outermost.
+func WithMiddleware(middleware ...Middleware) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.middleware = append(c.middleware, middleware...)
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// chain wraps handler in the given middleware for op
+func chain(middleware []Middleware, op Operation, handler RequestHandler) RequestHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw, next := middleware[i], handler
+		handler = func(req *http.Request) (*http.Response, error) {
+			return mw(op, req, next)
+		}
+	}
+	return handler
+}
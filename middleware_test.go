@@ -0,0 +1,58 @@
+package gocardless
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware_SeesOperationRequestAndResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tag") != "audited" {
+			t.Errorf("expected middleware header to reach the server")
+		}
+		w.Write([]byte(`{"payments":{"id":"PM123"}}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	var seen Operation
+	var status int
+	outer := func(op Operation, req *http.Request, next RequestHandler) (*http.Response, error) {
+		calls = append(calls, "outer")
+		seen = op
+		req.Header.Set("X-Tag", "audited")
+		res, err := next(req)
+		if res != nil {
+			status = res.StatusCode
+		}
+		return res, err
+	}
+	inner := func(op Operation, req *http.Request, next RequestHandler) (*http.Response, error) {
+		calls = append(calls, "inner")
+		return next(req)
+	}
+
+	config, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithMiddleware(outer, inner))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(config)
+
+	_, err = client.Payments.Cancel(context.TODO(), "PM123", PaymentCancelParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Operation{Service: "payments", Action: "cancel", Identity: "PM123"}
+	if seen != want {
+		t.Fatalf("expected operation %+v, got %+v", want, seen)
+	}
+	if len(calls) != 2 || calls[0] != "outer" || calls[1] != "inner" {
+		t.Fatalf("expected middleware to run in order, got %v", calls)
+	}
+	if status != http.StatusOK {
+		t.Fatalf("expected middleware to see response status 200, got %d", status)
+	}
+}
@@ -0,0 +1,305 @@
+// Package oauth implements the GoCardless OAuth flow used by partner
+// integrations to connect merchant accounts: sending the merchant to
+// GoCardless to authorise the partner's app, exchanging the code they return
+// with for an access token, and looking up the organisation it belongs to.
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gocardless "github.com/gocardless/gocardless-pro-go/v6"
+)
+
+const (
+
+	// Live connect environment
+	LiveConnectEndpoint = "https://connect.gocardless.com"
+
+	// Sandbox connect environment
+	SandboxConnectEndpoint = "https://connect-sandbox.gocardless.com"
+)
+
+// DefaultTimeout is the timeout of the HTTP client used unless one is
+// configured with WithHTTPClient
+const DefaultTimeout = 30 * time.Second
+
+// Scopes an access token can be granted
+const (
+	ScopeReadWrite = "read_write"
+	ScopeReadOnly  = "read_only"
+)
+
+// ErrStateMismatch is returned by VerifyState when the state returned to the
+// redirect URI does not match the state the flow was started with
+var ErrStateMismatch = errors.New("oauth state mismatch")
+
+// Option used to initialise the client
+type Option func(*Client) error
+
+// Client performs the OAuth flow for a partner app
+type Client struct {
+	clientID        string
+	clientSecret    string
+	redirectURI     string
+	connectEndpoint string
+	apiEndpoint     string
+	httpClient      *http.Client
+}
+
+// WithConnectEndpoint configures the endpoint hosting the OAuth flow
+func WithConnectEndpoint(endpoint string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		c.connectEndpoint = strings.TrimSuffix(u.String(), "/")
+		return nil
+	}
+}
+
+// WithAPIEndpoint configures the endpoint of the API that configs for
+// connected merchants are created for
+func WithAPIEndpoint(endpoint string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		c.apiEndpoint = u.String()
+		return nil
+	}
+}
+
+// WithSandbox configures the client to use the sandbox environment
+func WithSandbox() Option {
+	return func(c *Client) error {
+		c.connectEndpoint = SandboxConnectEndpoint
+		c.apiEndpoint = gocardless.SandboxEndpoint
+		return nil
+	}
+}
+
+// WithHTTPClient configures the net/http client
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		if client == nil {
+			return errors.New("HTTP client required")
+		}
+		c.httpClient = client
+		return nil
+	}
+}
+
+// NewClient returns a Client for the partner app with the given credentials
+// and redirect URI, using the live environment by default
+func NewClient(clientID, clientSecret, redirectURI string, opts ...Option) (*Client, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, errors.New("client ID and secret required")
+	}
+	if redirectURI == "" {
+		return nil, errors.New("redirect URI required")
+	}
+
+	c := &Client{
+		clientID:        clientID,
+		clientSecret:    clientSecret,
+		redirectURI:     redirectURI,
+		connectEndpoint: LiveConnectEndpoint,
+		apiEndpoint:     gocardless.LiveEndpoint,
+		httpClient:      &http.Client{Timeout: DefaultTimeout},
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Prefill holds details of the merchant used to prefill the signup form
+type Prefill struct {
+	Email            string
+	GivenName        string
+	FamilyName       string
+	OrganisationName string
+	CountryCode      string
+}
+
+// AuthoriseParams parameters
+type AuthoriseParams struct {
+	// Scope is the access the partner app is requesting, ScopeReadWrite
+	// unless set
+	Scope string
+	// State is returned unchanged to the redirect URI, and should be
+	// generated with NewState to protect against CSRF
+	State string
+	// InitialView is the form shown first, either "signup" or "login"
+	InitialView string
+	// Language is the language the flow is shown in, e.g. "en"
+	Language string
+	Prefill  Prefill
+}
+
+// AuthoriseURL returns the URL to send a merchant to in order to authorise
+// the partner app
+func (c *Client) AuthoriseURL(p AuthoriseParams) string {
+	scope := p.Scope
+	if scope == "" {
+		scope = ScopeReadWrite
+	}
+
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", c.clientID)
+	v.Set("redirect_uri", c.redirectURI)
+	v.Set("scope", scope)
+	v.Set("access_type", "offline")
+	set := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	set("state", p.State)
+	set("initial_view", p.InitialView)
+	set("language", p.Language)
+	set("prefill[email]", p.Prefill.Email)
+	set("prefill[given_name]", p.Prefill.GivenName)
+	set("prefill[family_name]", p.Prefill.FamilyName)
+	set("prefill[organisation_name]", p.Prefill.OrganisationName)
+	set("prefill[country_code]", p.Prefill.CountryCode)
+
+	return c.connectEndpoint + "/oauth/authorize?" + v.Encode()
+}
+
+// Token is an access token issued for a connected merchant
+type Token struct {
+	AccessToken    string `json:"access_token"`
+	Scope          string `json:"scope"`
+	TokenType      string `json:"token_type"`
+	Email          string `json:"email"`
+	OrganisationID string `json:"organisation_id"`
+}
+
+// ExchangeCode exchanges the code a merchant is redirected back with for an
+// access token
+func (c *Client) ExchangeCode(ctx context.Context, code string) (*Token, error) {
+	var token Token
+	err := c.post(ctx, "/oauth/access_token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.redirectURI},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+	}, &token)
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("missing access token")
+	}
+	return &token, nil
+}
+
+// TokenInfo describes an access token
+type TokenInfo struct {
+	Active         bool   `json:"active"`
+	ClientID       string `json:"client_id"`
+	OrganisationID string `json:"organisation_id"`
+	Scope          string `json:"scope"`
+}
+
+// Introspect looks up the details of an access token, including the ID of
+// the organisation it belongs to
+func (c *Client) Introspect(ctx context.Context, accessToken string) (*TokenInfo, error) {
+	var info TokenInfo
+	err := c.post(ctx, "/oauth/introspect", url.Values{
+		"token":         {accessToken},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+	}, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Config returns a config for making API requests on behalf of the merchant
+// the token was issued for
+func (c *Client) Config(token *Token, opts ...gocardless.ConfigOption) (gocardless.Config, error) {
+	if token == nil {
+		return nil, errors.New("token required")
+	}
+	opts = append([]gocardless.ConfigOption{gocardless.WithEndpoint(c.apiEndpoint)}, opts...)
+	return gocardless.NewConfig(token.AccessToken, opts...)
+}
+
+// Error is returned when the OAuth flow fails
+type Error struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth: %s: %s", e.Code, e.Description)
+	}
+	return fmt.Sprintf("oauth: %s", e.Code)
+}
+
+func (c *Client) post(ctx context.Context, path string, form url.Values, out interface{}) error {
+	req, err := http.NewRequest("POST", c.connectEndpoint+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		e := &Error{StatusCode: res.StatusCode}
+		if err := json.NewDecoder(res.Body).Decode(e); err != nil || e.Code == "" {
+			e.Code = res.Status
+		}
+		return e
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// NewState generates a random state to start the flow with, which should be
+// stored against the merchant's session and checked with VerifyState when
+// they are redirected back
+func NewState() string {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		panic("failed to generate random state")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// VerifyState checks the state a merchant was redirected back with matches
+// the state the flow was started with, returning ErrStateMismatch otherwise
+func VerifyState(expected, got string) error {
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(got)) != 1 {
+		return ErrStateMismatch
+	}
+	return nil
+}
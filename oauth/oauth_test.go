@@ -0,0 +1,177 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := NewClient("client_id", "client_secret", "https://partner.example.com/callback",
+		WithConnectEndpoint(server.URL), WithAPIEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestAuthoriseURL(t *testing.T) {
+	c, err := NewClient("client_id", "client_secret", "https://partner.example.com/callback", WithSandbox())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(c.AuthoriseURL(AuthoriseParams{
+		State:       "state123",
+		InitialView: "signup",
+		Prefill: Prefill{
+			Email:            "merchant@example.com",
+			OrganisationName: "Acme",
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := u.Scheme + "://" + u.Host + u.Path; got != SandboxConnectEndpoint+"/oauth/authorize" {
+		t.Fatalf("unexpected authorise URL %s", got)
+	}
+	expected := map[string]string{
+		"response_type":              "code",
+		"client_id":                  "client_id",
+		"redirect_uri":               "https://partner.example.com/callback",
+		"scope":                      ScopeReadWrite,
+		"state":                      "state123",
+		"initial_view":               "signup",
+		"prefill[email]":             "merchant@example.com",
+		"prefill[organisation_name]": "Acme",
+	}
+	q := u.Query()
+	for key, value := range expected {
+		if q.Get(key) != value {
+			t.Errorf("expected %s to be %q, got %q", key, value, q.Get(key))
+		}
+	}
+	if q.Has("prefill[given_name]") {
+		t.Error("expected unset prefill fields to be omitted")
+	}
+}
+
+func TestExchangeCode(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/oauth/access_token" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != "code123" {
+			t.Errorf("unexpected form %v", r.PostForm)
+			return
+		}
+		if r.PostForm.Get("client_secret") != "client_secret" {
+			t.Error("expected client credentials to be sent")
+			return
+		}
+		w.Write([]byte(`{"access_token":"sandbox_abc","scope":"read_write","token_type":"bearer","organisation_id":"OR123"}`))
+	})
+
+	token, err := c.ExchangeCode(context.TODO(), "code123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "sandbox_abc" || token.OrganisationID != "OR123" {
+		t.Fatalf("unexpected token %+v", token)
+	}
+
+	cfg, err := c.Config(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Token() != "sandbox_abc" {
+		t.Fatalf("expected config for the merchant's token, got %q", cfg.Token())
+	}
+	if _, err := c.Config(nil); err == nil {
+		t.Fatal("expected error for a nil token")
+	}
+}
+
+func TestExchangeCode_Timeout(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+	if c.httpClient.Timeout != DefaultTimeout {
+		t.Fatalf("expected the default timeout, got %v", c.httpClient.Timeout)
+	}
+
+	c.httpClient = &http.Client{Timeout: 20 * time.Millisecond}
+	if _, err := c.ExchangeCode(context.TODO(), "code123"); err == nil {
+		t.Fatal("expected a hung token exchange to time out")
+	}
+}
+
+func TestExchangeCode_Error(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"code has expired"}`))
+	})
+
+	_, err := c.ExchangeCode(context.TODO(), "code123")
+	var oauthErr *Error
+	if !errors.As(err, &oauthErr) {
+		t.Fatalf("expected an oauth error, got %v", err)
+	}
+	if oauthErr.Code != "invalid_grant" || oauthErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected error %+v", oauthErr)
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/introspect" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			return
+		}
+		r.ParseForm()
+		if r.PostForm.Get("token") != "sandbox_abc" {
+			t.Errorf("unexpected token %q", r.PostForm.Get("token"))
+			return
+		}
+		w.Write([]byte(`{"active":true,"client_id":"client_id","organisation_id":"OR123","scope":"read_write"}`))
+	})
+
+	info, err := c.Introspect(context.TODO(), "sandbox_abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Active || info.OrganisationID != "OR123" {
+		t.Fatalf("unexpected token info %+v", info)
+	}
+}
+
+func TestState(t *testing.T) {
+	state := NewState()
+	if state == NewState() {
+		t.Fatal("expected states to be unique")
+	}
+	if err := VerifyState(state, state); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyState(state, "forged"); !errors.Is(err, ErrStateMismatch) {
+		t.Fatalf("expected a mismatch, got %v", err)
+	}
+	if err := VerifyState("", ""); !errors.Is(err, ErrStateMismatch) {
+		t.Fatal("expected an empty state to be rejected")
+	}
+}
@@ -2,8 +2,11 @@ package gocardless
 
 import (
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 const (
@@ -13,8 +16,26 @@ const (
 
 	// Sandbox environment
 	SandboxEndpoint = "https://api-sandbox.gocardless.com"
+
+	// DefaultAPIVersion is the version of the API requests are made against
+	// unless configured otherwise
+	DefaultAPIVersion = "2015-07-06"
 )
 
+// knownAPIVersions are the versions of the API this library can be pinned to
+var knownAPIVersions = []string{
+	"2015-07-06",
+}
+
+func validateAPIVersion(version string) error {
+	for _, v := range knownAPIVersions {
+		if v == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown API version %q", version)
+}
+
 // ConfigOption used to initialise the client
 type ConfigOption func(Config) error
 
@@ -25,9 +46,26 @@ type Config interface {
 }
 
 type config struct {
-	token    string
-	endpoint string
-	client   *http.Client
+	token           string
+	endpoint        string
+	apiVersion      string
+	client          *http.Client
+	middleware      []Middleware
+	retryPolicy     *RetryPolicy
+	rateLimiter     *RateLimiter
+	tokenLimiters   *tokenLimiters
+	tokenSource     TokenSource
+	requestSigner   *RequestSigner
+	transport       transportSettings
+	doer            Doer
+	readOnly        bool
+	dryRun          *DryRunRecorder
+	logger          *slog.Logger
+	tracer          Tracer
+	metrics         MetricsRecorder
+	readBreaker     *CircuitBreaker
+	writeBreaker    *CircuitBreaker
+	serviceBreakers map[string]*CircuitBreaker
 }
 
 func (c *config) Token() string {
@@ -42,6 +80,20 @@ func (c *config) Client() *http.Client {
 	return c.client
 }
 
+func (c *config) APIVersion() string {
+	return c.apiVersion
+}
+
+// APIVersionOf returns the version of the API requests made with cfg are
+// made against. Configs which do not implement an APIVersion method use
+// DefaultAPIVersion.
+func APIVersionOf(cfg Config) string {
+	if v, ok := cfg.(interface{ APIVersion() string }); ok && v.APIVersion() != "" {
+		return v.APIVersion()
+	}
+	return DefaultAPIVersion
+}
+
 // WithEndpoint configures the endpoint hosting the API
 func WithEndpoint(endpoint string) ConfigOption {
 	return func(cfg Config) error {
@@ -70,14 +122,45 @@ func WithClient(client *http.Client) ConfigOption {
 	}
 }
 
-func NewConfig(token string, configOpts ...ConfigOption) (Config, error) {
-	if token == "" {
-		return nil, errors.New("token required")
+// Doer sends HTTP requests, and is satisfied by *http.Client
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WithDoer sends requests through doer instead of an *http.Client, taking
+// precedence over WithClient
+func WithDoer(doer Doer) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.doer = doer
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
 	}
+}
 
+// WithAPIVersion pins the version of the API requests are made against
+func WithAPIVersion(version string) ConfigOption {
+	return func(cfg Config) error {
+		if err := validateAPIVersion(version); err != nil {
+			return err
+		}
+		if c, ok := cfg.(*config); ok {
+			c.apiVersion = version
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+func NewConfig(token string, configOpts ...ConfigOption) (Config, error) {
 	config := &config{
-		token:    token,
-		endpoint: LiveEndpoint,
+		token:      token,
+		endpoint:   LiveEndpoint,
+		apiVersion: DefaultAPIVersion,
+		transport:  defaultTransportSettings,
 	}
 
 	for _, configOpt := range configOpts {
@@ -86,6 +169,20 @@ func NewConfig(token string, configOpts ...ConfigOption) (Config, error) {
 		}
 	}
 
+	if token == "" && config.tokenSource == nil {
+		return nil, errors.New("token required")
+	}
+
+	if config.client == nil && config.doer == nil {
+		config.client = newHTTPClient(config.transport)
+	}
+
+	if config.rateLimiter != nil {
+		config.tokenLimiters = &tokenLimiters{
+			limiters: map[string]*RateLimiter{token: config.rateLimiter},
+		}
+	}
+
 	return config, nil
 }
 
@@ -93,9 +190,14 @@ func NewConfig(token string, configOpts ...ConfigOption) (Config, error) {
 type RequestOption func(*requestOptions) error
 
 type requestOptions struct {
-	idempotencyKey string
-	retries        int
-	headers        map[string]string
+	idempotencyKey   string
+	retryPolicy      RetryPolicy
+	headers          map[string]string
+	resolveConflicts bool
+	responseInfo     *ResponseInfo
+	apiVersion       string
+	accessToken      string
+	timeout          time.Duration
 }
 
 // WithIdempotencyKey sets an idempotency key so multiple calls to a
@@ -110,10 +212,59 @@ func WithIdempotencyKey(key string) RequestOption {
 	}
 }
 
+// callerIdempotencyKey reports whether the caller chose the idempotency key
+// for the request, either directly or through its headers
+func (opts *requestOptions) callerIdempotencyKey() bool {
+	return opts.idempotencyKey != "" || opts.hasHeader("Idempotency-Key")
+}
+
+// hasHeader reports whether the caller set the named header
+func (opts *requestOptions) hasHeader(name string) bool {
+	for key := range opts.headers {
+		if http.CanonicalHeaderKey(key) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // WithRetries sets the amount of total retries to make for the request
 func WithRetries(n int) RequestOption {
 	return func(opts *requestOptions) error {
-		opts.retries = n
+		opts.retryPolicy.MaxAttempts = n
+		return nil
+	}
+}
+
+// WithRequestRetryPolicy overrides the retry policy for this request
+func WithRequestRetryPolicy(policy RetryPolicy) RequestOption {
+	return func(opts *requestOptions) error {
+		opts.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithRequestAPIVersion overrides the version of the API this request is
+// made against
+func WithRequestAPIVersion(version string) RequestOption {
+	return func(opts *requestOptions) error {
+		if err := validateAPIVersion(version); err != nil {
+			return err
+		}
+		opts.apiVersion = version
+		return nil
+	}
+}
+
+// WithAccessToken makes this request with the given access token instead of
+// the one the client was configured with. If the client is rate limited,
+// requests made with it are throttled by a rate limiter kept for that token.
+func WithAccessToken(token string) RequestOption {
+	return func(opts *requestOptions) error {
+		if token == "" {
+			return errors.New("token required")
+		}
+		opts.accessToken = token
 		return nil
 	}
 }
@@ -130,3 +281,26 @@ func WithHeaders(headers map[string]string) RequestOption {
 		return nil
 	}
 }
+
+// WithConflictResolution makes a create which fails because its idempotency
+// key was already used return the resource originally created with that key,
+// fetching it from the API, instead of an idempotent creation conflict error.
+// It has no effect on other requests.
+func WithConflictResolution() RequestOption {
+	return func(opts *requestOptions) error {
+		opts.resolveConflicts = true
+		return nil
+	}
+}
+
+// WithTimeout sets a deadline for the request, including any retries, on top
+// of any deadline of the context it is made with
+func WithTimeout(d time.Duration) RequestOption {
+	return func(opts *requestOptions) error {
+		if d <= 0 {
+			return errors.New("timeout must be positive")
+		}
+		opts.timeout = d
+		return nil
+	}
+}
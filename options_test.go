@@ -1,6 +1,11 @@
 package gocardless
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestEndpointForToken(t *testing.T) {
 	tests := []struct {
@@ -50,3 +55,82 @@ func TestEndpointForConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestWithAPIVersion(t *testing.T) {
+	config, err := NewConfig("dummy_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := APIVersionOf(config); got != DefaultAPIVersion {
+		t.Fatalf("expected default API version %s, got %s", DefaultAPIVersion, got)
+	}
+
+	config, err = NewConfig("dummy_token", WithAPIVersion("2015-07-06"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := APIVersionOf(config); got != "2015-07-06" {
+		t.Fatalf("expected pinned API version, got %s", got)
+	}
+
+	if _, err := NewConfig("dummy_token", WithAPIVersion("2099-01-01")); err == nil {
+		t.Fatal("expected error for unknown API version")
+	}
+
+	if got := APIVersionOf(customConfig{}); got != DefaultAPIVersion {
+		t.Fatalf("expected configs without a version to use the default, got %s", got)
+	}
+}
+
+// customConfig is a Config implemented outside of NewConfig
+type customConfig struct{}
+
+func (customConfig) Token() string        { return "dummy_token" }
+func (customConfig) Endpoint() string     { return "https://api.example.com" }
+func (customConfig) Client() *http.Client { return nil }
+
+func TestWithRequestAPIVersion(t *testing.T) {
+	var versions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions = append(versions, r.Header.Get("GoCardless-Version"))
+		w.Write([]byte(`{"customers":{}}`))
+	}))
+	defer server.Close()
+
+	client, _ := getClient(t, server.URL)
+	if _, err := client.Customers.Get(context.TODO(), "CU123", WithRequestAPIVersion("2015-07-06")); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0] != "2015-07-06" {
+		t.Fatalf("expected request to be sent with the API version, got %v", versions)
+	}
+
+	_, err := client.Customers.Get(context.TODO(), "CU123", WithRequestAPIVersion("2099-01-01"))
+	if err == nil {
+		t.Fatal("expected error for unknown API version")
+	}
+	if len(versions) != 1 {
+		t.Fatal("expected request with unknown API version not to be sent")
+	}
+}
+
+func TestWithAccessToken(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"payments":{}}`))
+	}))
+	defer server.Close()
+
+	client, _ := getClient(t, server.URL)
+	if _, err := client.Payments.Get(context.TODO(), "PM123", WithAccessToken("merchant_token")); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer merchant_token" {
+		t.Fatalf("expected request to use the overridden token, got %q", auth)
+	}
+
+	if _, err := client.Payments.Get(context.TODO(), "PM123", WithAccessToken("")); err == nil {
+		t.Fatal("expected error for empty token")
+	}
+}
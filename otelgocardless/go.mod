@@ -0,0 +1,23 @@
+module github.com/gocardless/gocardless-pro-go/v6/otelgocardless
+
+go 1.21
+
+require (
+	github.com/gocardless/gocardless-pro-go/v6 v6.5.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/go-querystring v1.2.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/otel/metric v1.28.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
+
+// The tracing API this module adapts is first released in v6.5.0. Until then,
+// and while developing both modules together, build against the local copy.
+replace github.com/gocardless/gocardless-pro-go/v6 => ../
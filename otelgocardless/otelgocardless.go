@@ -0,0 +1,128 @@
+// Package otelgocardless traces requests made by gocardless-pro-go with
+// OpenTelemetry, and propagates the trace to the API with traceparent
+// headers. It is a separate module so that the library itself does not
+// depend on OpenTelemetry.
+package otelgocardless
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	gocardless "github.com/gocardless/gocardless-pro-go/v6"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by this package
+const instrumentationName = "github.com/gocardless/gocardless-pro-go/v6/otelgocardless"
+
+// Option configures the tracing
+type Option func(*options)
+
+type options struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the provider spans are created with, instead of
+// the global provider
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.provider = provider
+	}
+}
+
+// WithPropagator sets the propagator used to inject the trace into
+// requests, instead of W3C trace context
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(o *options) {
+		o.propagator = propagator
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		provider:   otel.GetTracerProvider(),
+		propagator: propagation.TraceContext{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTracing traces every request made with the config, injecting the
+// trace of each attempt into its headers
+func WithTracing(opts ...Option) gocardless.ConfigOption {
+	return func(cfg gocardless.Config) error {
+		if err := gocardless.WithTracer(NewTracer(opts...))(cfg); err != nil {
+			return err
+		}
+		return gocardless.WithMiddleware(Middleware(opts...))(cfg)
+	}
+}
+
+// NewTracer returns a gocardless.Tracer creating OpenTelemetry spans
+func NewTracer(opts ...Option) gocardless.Tracer {
+	o := newOptions(opts)
+	return &tracer{
+		tracer: o.provider.Tracer(instrumentationName, trace.WithInstrumentationVersion(gocardless.ClientLibVersion)),
+	}
+}
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+func (t *tracer) Start(ctx context.Context, name string) (context.Context, gocardless.Span) {
+	ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, &span{span: s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s *span) SetAttributes(attrs ...gocardless.Attribute) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	s.span.SetAttributes(kvs...)
+}
+
+func (s *span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
+
+// Middleware injects the trace of each attempt at a request into its
+// headers, as a traceparent header unless configured otherwise
+func Middleware(opts ...Option) gocardless.Middleware {
+	o := newOptions(opts)
+	return func(op gocardless.Operation, req *http.Request, next gocardless.RequestHandler) (*http.Response, error) {
+		o.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+		return next(req)
+	}
+}
@@ -0,0 +1,165 @@
+package otelgocardless
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gocardless "github.com/gocardless/gocardless-pro-go/v6"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware_InjectsTraceparent(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.gocardless.com/payments/PM123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var traceparent string
+	next := func(req *http.Request) (*http.Response, error) {
+		traceparent = req.Header.Get("traceparent")
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}
+	if _, err := Middleware()(gocardless.Operation{Service: "payments", Action: "get"}, req, next); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"; traceparent != expected {
+		t.Fatalf("expected traceparent %q, got %q", expected, traceparent)
+	}
+}
+
+func TestWithTracing(t *testing.T) {
+	var traceparents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if len(traceparents) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"message":"unavailable","type":"gocardless","code":503,"errors":[]}}`))
+			return
+		}
+		w.Header().Set("X-Request-Id", "RQ123")
+		w.Write([]byte(`{"payments":{"id":"PM123"}}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	cfg, err := gocardless.NewConfig("dummy_token", gocardless.WithEndpoint(server.URL), WithTracing(WithTracerProvider(provider)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := gocardless.New(cfg)
+	if _, err := client.Payments.Get(context.TODO(), "PM123"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected a span for the call and each attempt, got %d", len(spans))
+	}
+	first, second, call := spans[0], spans[1], spans[2]
+	if call.Name() != "gocardless.payments.get" || first.Name() != "gocardless.payments.get.attempt" || second.Name() != "gocardless.payments.get.attempt" {
+		t.Fatalf("unexpected span names %q, %q, %q", call.Name(), first.Name(), second.Name())
+	}
+	if call.SpanKind() != trace.SpanKindClient {
+		t.Fatalf("expected a client span, got %v", call.SpanKind())
+	}
+	for _, attempt := range []sdktrace.ReadOnlySpan{first, second} {
+		if attempt.Parent().SpanID() != call.SpanContext().SpanID() {
+			t.Fatal("expected attempt spans to be children of the call span")
+		}
+	}
+
+	expectAttributes(t, call, map[string]attribute.Value{
+		gocardless.AttributeService:  attribute.StringValue("payments"),
+		gocardless.AttributeAction:   attribute.StringValue("get"),
+		gocardless.AttributeMethod:   attribute.StringValue("GET"),
+		gocardless.AttributeIdentity: attribute.StringValue("PM123"),
+	})
+	expectAttributes(t, first, map[string]attribute.Value{
+		gocardless.AttributeAttempt:    attribute.IntValue(1),
+		gocardless.AttributeStatusCode: attribute.IntValue(http.StatusServiceUnavailable),
+	})
+	expectAttributes(t, second, map[string]attribute.Value{
+		gocardless.AttributeAttempt:    attribute.IntValue(2),
+		gocardless.AttributeStatusCode: attribute.IntValue(http.StatusOK),
+		gocardless.AttributeRequestID:  attribute.StringValue("RQ123"),
+	})
+	if _, ok := attributes(second)[gocardless.AttributeRetryReason]; !ok {
+		t.Error("expected the retry reason to be recorded on the retry")
+	}
+
+	if first.Status().Code != codes.Error || len(first.Events()) != 1 || first.Events()[0].Name != "exception" {
+		t.Fatalf("expected the failed attempt to record its error, got status %+v", first.Status())
+	}
+	if second.Status().Code != codes.Unset || call.Status().Code != codes.Unset {
+		t.Fatal("expected the successful attempt and call not to be marked as errors")
+	}
+
+	for i, attempt := range []sdktrace.ReadOnlySpan{first, second} {
+		if !strings.Contains(traceparents[i], attempt.SpanContext().SpanID().String()) {
+			t.Errorf("expected attempt %d to propagate its span, got traceparent %q", i+1, traceparents[i])
+		}
+	}
+}
+
+func TestWithTracing_RecordsCallError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"message":"not found","type":"invalid_api_usage","code":404,"errors":[{"reason":"resource_not_found"}]}}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	cfg, err := gocardless.NewConfig("dummy_token", gocardless.WithEndpoint(server.URL), WithTracing(WithTracerProvider(provider)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := gocardless.New(cfg)
+	if _, err := client.Payments.Get(context.TODO(), "PM404"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected a span for the call and its attempt, got %d", len(spans))
+	}
+	for _, s := range spans {
+		if s.Status().Code != codes.Error || !strings.HasPrefix(s.Status().Description, "not found") {
+			t.Errorf("expected %s to have an error status, got %+v", s.Name(), s.Status())
+		}
+	}
+}
+
+// attributes returns the attributes set on s by key
+func attributes(s sdktrace.ReadOnlySpan) map[string]attribute.Value {
+	attrs := make(map[string]attribute.Value)
+	for _, kv := range s.Attributes() {
+		attrs[string(kv.Key)] = kv.Value
+	}
+	return attrs
+}
+
+func expectAttributes(t *testing.T, s sdktrace.ReadOnlySpan, expected map[string]attribute.Value) {
+	t.Helper()
+	attrs := attributes(s)
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("expected %s to have %s %v, got %v", s.Name(), key, value.Emit(), attrs[key].Emit())
+		}
+	}
+}
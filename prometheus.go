@@ -0,0 +1,207 @@
+package gocardless
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultPrometheusBuckets are the upper bounds, in seconds, of the buckets
+// request durations are counted in
+var DefaultPrometheusBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// PrometheusMetrics is a MetricsRecorder aggregating metrics to be scraped
+// by Prometheus. It serves them in the Prometheus text format, so can be
+// registered as a HTTP handler without depending on a Prometheus client.
+type PrometheusMetrics struct {
+	mu           sync.Mutex
+	buckets      []float64
+	requests     map[requestSeries]uint64
+	retries      map[operationSeries]uint64
+	durations    map[operationSeries]*histogram
+	rateLimit    RateLimitState
+	hasRateLimit bool
+}
+
+type operationSeries struct {
+	service string
+	action  string
+}
+
+type requestSeries struct {
+	operationSeries
+	status    int
+	errorType string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusMetrics returns a PrometheusMetrics counting request
+// durations in the given buckets, or DefaultPrometheusBuckets if none
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultPrometheusBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &PrometheusMetrics{
+		buckets:   buckets,
+		requests:  map[requestSeries]uint64{},
+		retries:   map[operationSeries]uint64{},
+		durations: map[operationSeries]*histogram{},
+	}
+}
+
+func (p *PrometheusMetrics) RecordRequest(m RequestMetric) {
+	op := operationSeries{service: m.Service, action: m.Action}
+	seconds := m.Duration.Seconds()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[requestSeries{op, m.StatusCode, m.ErrorType}]++
+	if m.Attempt > 1 {
+		p.retries[op]++
+	}
+	h, ok := p.durations[op]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.durations[op] = h
+	}
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (p *PrometheusMetrics) RecordRateLimit(state RateLimitState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rateLimit = state
+	p.hasRateLimit = true
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	b := bufio.NewWriter(cw)
+
+	p.mu.Lock()
+	requests := make([]requestSeries, 0, len(p.requests))
+	for s := range p.requests {
+		requests = append(requests, s)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		a, b := requests[i], requests[j]
+		if a.operationSeries != b.operationSeries {
+			return a.operationSeries.less(b.operationSeries)
+		}
+		if a.status != b.status {
+			return a.status < b.status
+		}
+		return a.errorType < b.errorType
+	})
+	fmt.Fprintln(b, "# HELP gocardless_requests_total Attempts at requests to the GoCardless API.")
+	fmt.Fprintln(b, "# TYPE gocardless_requests_total counter")
+	for _, s := range requests {
+		fmt.Fprintf(b, "gocardless_requests_total{%s,status=\"%d\",error_type=%s} %d\n",
+			s.operationSeries.labels(), s.status, quoteLabel(s.errorType), p.requests[s])
+	}
+
+	fmt.Fprintln(b, "# HELP gocardless_retries_total Retried attempts at requests to the GoCardless API.")
+	fmt.Fprintln(b, "# TYPE gocardless_retries_total counter")
+	retried := make([]operationSeries, 0, len(p.retries))
+	for s := range p.retries {
+		retried = append(retried, s)
+	}
+	for _, s := range sortOperations(retried) {
+		fmt.Fprintf(b, "gocardless_retries_total{%s} %d\n", s.labels(), p.retries[s])
+	}
+
+	fmt.Fprintln(b, "# HELP gocardless_request_duration_seconds Duration of attempts at requests to the GoCardless API.")
+	fmt.Fprintln(b, "# TYPE gocardless_request_duration_seconds histogram")
+	timed := make([]operationSeries, 0, len(p.durations))
+	for s := range p.durations {
+		timed = append(timed, s)
+	}
+	for _, s := range sortOperations(timed) {
+		h := p.durations[s]
+		for i, bound := range p.buckets {
+			fmt.Fprintf(b, "gocardless_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				s.labels(), strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(b, "gocardless_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", s.labels(), h.count)
+		fmt.Fprintf(b, "gocardless_request_duration_seconds_sum{%s} %s\n", s.labels(), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(b, "gocardless_request_duration_seconds_count{%s} %d\n", s.labels(), h.count)
+	}
+
+	if p.hasRateLimit {
+		fmt.Fprintln(b, "# HELP gocardless_rate_limit_limit Requests allowed per rate limit window.")
+		fmt.Fprintln(b, "# TYPE gocardless_rate_limit_limit gauge")
+		fmt.Fprintf(b, "gocardless_rate_limit_limit %d\n", p.rateLimit.Limit)
+		fmt.Fprintln(b, "# HELP gocardless_rate_limit_remaining Requests remaining in the current rate limit window.")
+		fmt.Fprintln(b, "# TYPE gocardless_rate_limit_remaining gauge")
+		fmt.Fprintf(b, "gocardless_rate_limit_remaining %d\n", p.rateLimit.Remaining)
+		if !p.rateLimit.Reset.IsZero() {
+			fmt.Fprintln(b, "# HELP gocardless_rate_limit_reset_timestamp_seconds When the current rate limit window resets.")
+			fmt.Fprintln(b, "# TYPE gocardless_rate_limit_reset_timestamp_seconds gauge")
+			fmt.Fprintf(b, "gocardless_rate_limit_reset_timestamp_seconds %d\n", p.rateLimit.Reset.Unix())
+		}
+	}
+	p.mu.Unlock()
+
+	err := b.Flush()
+	return cw.n, err
+}
+
+func (s operationSeries) less(o operationSeries) bool {
+	if s.service != o.service {
+		return s.service < o.service
+	}
+	return s.action < o.action
+}
+
+func (s operationSeries) labels() string {
+	return "service=" + quoteLabel(s.service) + ",action=" + quoteLabel(s.action)
+}
+
+func sortOperations(series []operationSeries) []operationSeries {
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].less(series[j])
+	})
+	return series
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,207 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles requests client-side so they stay within the API's
+// per-token rate limit, rather than relying on being rate limited by the
+// API. It is a token bucket whose size is learned from the RateLimit-*
+// headers on each response, and is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	window time.Duration
+	limit  int
+	tokens float64
+	last   time.Time
+	// reset is when the API will next allow requests, set while the API
+	// reports the limit as exhausted
+	reset time.Time
+}
+
+// RateLimitState is a snapshot of a RateLimiter
+type RateLimitState struct {
+	// Limit is the number of requests allowed per window, or zero if it
+	// has not been learned yet
+	Limit int
+	// Remaining is the number of requests that can be made without waiting
+	Remaining int
+	// Reset is when the API will next allow requests, if the limit is
+	// currently exhausted
+	Reset time.Time
+}
+
+// NewRateLimiter returns a RateLimiter which refills over the given window,
+// which is a minute for the GoCardless API. Until the limit is learned from
+// a response, requests are not throttled.
+func NewRateLimiter(window time.Duration) *RateLimiter {
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &RateLimiter{window: window}
+}
+
+// WithRateLimiter throttles all requests made with the config through the
+// given RateLimiter, which may also be shared between configs using the same
+// access token
+func WithRateLimiter(limiter *RateLimiter) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.rateLimiter = limiter
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// tokenLimiters are the rate limiters for each access token requests are
+// made with, shared by a config and every config derived from it so that
+// requests for the same token are throttled together
+type tokenLimiters struct {
+	mu       sync.Mutex
+	limiters map[string]*RateLimiter
+}
+
+// limiterFor returns the rate limiter throttling requests made with token,
+// or nil if the config is not rate limited
+func (c *config) limiterFor(token string) *RateLimiter {
+	if c.rateLimiter == nil || token == c.token {
+		return c.rateLimiter
+	}
+	if c.tokenLimiters == nil {
+		// Only configs made by NewConfig share limiters with derived ones
+		return NewRateLimiter(c.rateLimiter.window)
+	}
+	c.tokenLimiters.mu.Lock()
+	defer c.tokenLimiters.mu.Unlock()
+	l, ok := c.tokenLimiters.limiters[token]
+	if !ok {
+		l = NewRateLimiter(c.rateLimiter.window)
+		c.tokenLimiters.limiters[token] = l
+	}
+	return l
+}
+
+// Wait blocks until a request can be made within the rate limit, or ctx
+// is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	return l.wait(ctx, 0)
+}
+
+// wait is Wait, failing instead if the request cannot be made within max,
+// if set
+func (l *RateLimiter) wait(ctx context.Context, max time.Duration) error {
+	start := time.Now()
+	for {
+		now := time.Now()
+		l.mu.Lock()
+		wait := l.take(now)
+		l.mu.Unlock()
+		if wait == 0 {
+			return nil
+		}
+		if total := now.Sub(start) + wait; max > 0 && total > max {
+			return fmt.Errorf("rate limit wait of %v exceeds maximum of %v: %w", total, max, ErrRateLimitExceeded)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// State returns the current state of the limiter
+func (l *RateLimiter) State() RateLimitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.refill(now)
+	state := RateLimitState{
+		Limit:     l.limit,
+		Remaining: int(l.tokens),
+	}
+	if now.Before(l.reset) {
+		state.Reset = l.reset
+	}
+	return state
+}
+
+// take removes a token from the bucket, returning zero if it succeeded or
+// otherwise how long to wait before trying again
+func (l *RateLimiter) take(now time.Time) time.Duration {
+	if l.limit == 0 {
+		return 0
+	}
+	l.refill(now)
+	if now.Before(l.reset) {
+		return l.reset.Sub(now)
+	}
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	perToken := l.window / time.Duration(l.limit)
+	return time.Duration((1 - l.tokens) * float64(perToken))
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	if l.limit == 0 {
+		return
+	}
+	if !l.reset.IsZero() && !now.Before(l.reset) {
+		// The API's window has reset, so the full limit is available
+		l.tokens = float64(l.limit)
+		l.reset = time.Time{}
+	} else if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += float64(l.limit) * elapsed.Seconds() / l.window.Seconds()
+		if l.tokens > float64(l.limit) {
+			l.tokens = float64(l.limit)
+		}
+	}
+	l.last = now
+}
+
+// observe updates the limiter from the RateLimit-* headers of a response.
+// The API's view takes precedence, as other clients may share the token.
+func (l *RateLimiter) observe(res *http.Response) {
+	limit, err := strconv.Atoi(res.Header.Get("RateLimit-Limit"))
+	if err != nil || limit <= 0 {
+		return
+	}
+	remaining, err := strconv.Atoi(res.Header.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.limit == 0 {
+		l.tokens = float64(limit)
+		l.last = now
+	}
+	l.limit = limit
+	l.refill(now)
+	if float64(remaining) < l.tokens {
+		l.tokens = float64(remaining)
+	}
+	if remaining == 0 {
+		if reset, err := parseRateLimitReset(res.Header.Get("RateLimit-Reset")); err == nil {
+			l.reset = reset
+		}
+	}
+}
+
+func parseRateLimitReset(v string) (time.Time, error) {
+	t, err := time.Parse(time.RFC1123, v)
+	if err != nil {
+		t, err = time.Parse(time.RFC1123Z, v)
+	}
+	return t, err
+}
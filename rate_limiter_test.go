@@ -0,0 +1,154 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func rateLimitResponse(limit, remaining string, reset time.Time) *http.Response {
+	h := http.Header{}
+	h.Set("RateLimit-Limit", limit)
+	h.Set("RateLimit-Remaining", remaining)
+	if !reset.IsZero() {
+		h.Set("RateLimit-Reset", reset.UTC().Format(time.RFC1123))
+	}
+	return &http.Response{Header: h}
+}
+
+func TestRateLimiter_UnthrottledUntilLearned(t *testing.T) {
+	l := NewRateLimiter(time.Minute)
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state := l.State(); state.Limit != 0 {
+		t.Fatalf("expected no limit before a response is seen, got %d", state.Limit)
+	}
+}
+
+func TestRateLimiter_LearnsFromHeaders(t *testing.T) {
+	l := NewRateLimiter(time.Minute)
+	l.observe(rateLimitResponse("1000", "3", time.Time{}))
+
+	state := l.State()
+	if state.Limit != 1000 {
+		t.Fatalf("expected limit 1000, got %d", state.Limit)
+	}
+	if state.Remaining != 3 {
+		t.Fatalf("expected 3 remaining, got %d", state.Remaining)
+	}
+}
+
+func TestRateLimiter_WaitsForResetWhenExhausted(t *testing.T) {
+	l := NewRateLimiter(time.Minute)
+	reset := time.Now().Add(time.Hour)
+	l.observe(rateLimitResponse("1000", "0", reset))
+
+	if state := l.State(); state.Reset.IsZero() {
+		t.Fatal("expected reset time to be reported while exhausted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected wait to last until the context is done, got %v", err)
+	}
+
+	if wait := l.take(reset.Add(time.Second)); wait != 0 {
+		t.Fatalf("expected tokens to be available after reset, got wait %v", wait)
+	}
+}
+
+func TestRateLimiter_ThrottlesAcrossGoroutines(t *testing.T) {
+	l := NewRateLimiter(100 * time.Millisecond)
+	l.observe(rateLimitResponse("5", "5", time.Time{}))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Wait(context.TODO())
+		}()
+	}
+	wg.Wait()
+
+	// Five requests fit in the bucket, the rest refill at 20ms each
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("expected requests to be throttled, took %v", elapsed)
+	}
+}
+
+func TestWithRateLimiter_SharedAcrossServices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Limit", "1000")
+		w.Header().Set("RateLimit-Remaining", "998")
+		w.Write([]byte(`{"payments":{},"mandates":{}}`))
+	}))
+	defer server.Close()
+
+	l := NewRateLimiter(time.Minute)
+	config, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithRateLimiter(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(config)
+
+	if _, err := client.Payments.Get(context.TODO(), "PM123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Mandates.Get(context.TODO(), "MD123"); err != nil {
+		t.Fatal(err)
+	}
+
+	state := l.State()
+	if state.Limit != 1000 {
+		t.Fatalf("expected limit to be learned from responses, got %d", state.Limit)
+	}
+	if state.Remaining > 998 {
+		t.Fatalf("expected remaining to follow the API, got %d", state.Remaining)
+	}
+}
+
+func TestRateLimiter_RespectsMaxWait(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("RateLimit-Limit", "1000")
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", time.Now().Add(time.Hour).UTC().Format(time.RFC1123))
+		w.Write([]byte(`{"payments":{}}`))
+	}))
+	defer server.Close()
+
+	l := NewRateLimiter(time.Minute)
+	config, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithRateLimiter(l),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1, MaxWait: time.Second}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(config)
+
+	if _, err := client.Payments.Get(context.TODO(), "PM123"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = client.Payments.Get(context.TODO(), "PM123")
+	if !errors.Is(err, ErrRateLimitExceeded) || !strings.Contains(err.Error(), "exceeds maximum of 1s") {
+		t.Fatalf("expected the wait to exceed the maximum, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected to fail without waiting, took %v", elapsed)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the throttled request not to be sent, got %d calls", calls)
+	}
+}
@@ -0,0 +1,137 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Do makes a request to any endpoint of the API, such as one this library
+// does not support yet, with the same authentication, headers, idempotency
+// keys, retries and error handling as the services. path is relative to the
+// configured endpoint, e.g. "/payments/PM123/actions/cancel". params are
+// encoded into the query string of GET requests, from either a url.Values
+// or a struct with url tags, and as the JSON body of others, so must include
+// the resource envelope, e.g. map[string]interface{}{"payments": ...}. The
+// response is decoded into out, which may be a pointer to any struct or a
+// *json.RawMessage.
+//
+// Requests to endpoints the services call are described, signed and have
+// idempotent creation conflicts resolved just as when made by the service.
+// Other endpoints are assumed to follow the API's conventions, e.g. a POST to
+// "/widgets" creates a widget which can be fetched from "/widgets/{id}".
+func (s *Service) Do(ctx context.Context, method, path string, params, out interface{}, opts ...RequestOption) error {
+	if !strings.HasPrefix(path, "/") {
+		return errors.New("path must begin with /")
+	}
+	if out == nil {
+		return errors.New("out required")
+	}
+
+	r := &apiRequest{
+		method: strings.ToUpper(method),
+		path:   path,
+	}
+	describe(r)
+	if r.mutating() {
+		r.body = params
+	} else if v, ok := params.(url.Values); ok {
+		if len(v) > 0 {
+			r.path += "?" + v.Encode()
+		}
+	} else {
+		r.query = params
+	}
+
+	return execute(ctx, s.config, r, out, opts)
+}
+
+// endpoint describes a request to an endpoint which does not follow the
+// API's conventions, in the terms the service calling it uses. A "*"
+// segment in path matches the identity of the resource acted on.
+type endpoint struct {
+	method, path    string
+	service, action string
+	getPath         string
+	signed          bool
+}
+
+// irregularEndpoints are the endpoints called by the services which
+// describe would otherwise describe differently
+var irregularEndpoints = []endpoint{
+	{method: "POST", path: "/bank_details_lookups", service: "bank_details_lookups", action: "create"},
+	{method: "POST", path: "/billing_request_flows", service: "billing_request_flows", action: "create"},
+	{method: "POST", path: "/billing_requests/create_with_actions", service: "billing_request_with_actions", action: "create_with_actions"},
+	{method: "GET", path: "/billing_requests/*/institutions", service: "institutions", action: "list_for_billing_request"},
+	{method: "POST", path: "/blocks/block_by_ref", service: "blocks", action: "block_by_ref"},
+	{method: "POST", path: "/branding/logos", service: "logos", action: "create_for_creditor"},
+	{method: "POST", path: "/branding/payer_themes", service: "payer_themes", action: "create_for_creditor"},
+	{method: "DELETE", path: "/customers/*", service: "customers", action: "remove"},
+	{method: "GET", path: "/funds_availability/*", service: "funds_availabilities", action: "check"},
+	{method: "POST", path: "/mandate_import_entries", service: "mandate_import_entries", action: "create"},
+	{method: "POST", path: "/mandate_pdfs", service: "mandate_pdfs", action: "create"},
+	{method: "POST", path: "/outbound_payment_imports", service: "outbound_payment_imports", action: "create", getPath: "/outbound_payment_imports/%v", signed: true},
+	{method: "POST", path: "/outbound_payments", service: "outbound_payments", action: "create", getPath: "/outbound_payments/%v", signed: true},
+	{method: "GET", path: "/outbound_payments/stats", service: "outbound_payments", action: "stats"},
+	{method: "POST", path: "/outbound_payments/withdrawal", service: "outbound_payments", action: "withdraw", getPath: "/outbound_payments/%v", signed: true},
+	{method: "POST", path: "/outbound_payments/*/actions/approve", service: "outbound_payments", action: "approve", signed: true},
+	{method: "GET", path: "/payment_accounts/*/transactions", service: "payment_account_transactions", action: "list"},
+	{method: "GET", path: "/transferred_mandates/*", service: "transferred_mandates", action: "transferred_mandates"},
+	{method: "POST", path: "/verification_details", service: "verification_details", action: "create"},
+}
+
+// match reports whether a request to the path split into segments is made
+// to e, returning the identity it acts on
+func (e *endpoint) match(method string, segments []string) (identity string, ok bool) {
+	pattern := strings.Split(strings.Trim(e.path, "/"), "/")
+	if method != e.method || len(pattern) != len(segments) {
+		return "", false
+	}
+	for i, p := range pattern {
+		switch p {
+		case "*":
+			identity = segments[i]
+		case segments[i]:
+		default:
+			return "", false
+		}
+	}
+	return identity, true
+}
+
+// describe derives the service, identity and action of a request made with
+// Do from its method and path, in the same terms the service calling the
+// same endpoint uses, so that it is described consistently to middleware,
+// logs, traces, metrics and circuit breakers. It also decides whether r is
+// signed and how conflicts creating a resource are resolved.
+func describe(r *apiRequest) {
+	segments := strings.Split(strings.Trim(strings.SplitN(r.path, "?", 2)[0], "/"), "/")
+	for _, e := range irregularEndpoints {
+		if identity, ok := e.match(r.method, segments); ok {
+			r.service, r.identity, r.action = e.service, identity, e.action
+			r.getPath, r.signed = e.getPath, e.signed
+			return
+		}
+	}
+
+	r.service = segments[0]
+	if len(segments) > 1 {
+		r.identity = segments[1]
+	}
+	switch {
+	case len(segments) == 4 && segments[2] == "actions":
+		r.action = segments[3]
+	case r.method == "GET" && r.identity != "":
+		r.action = "get"
+	case r.method == "GET":
+		r.action = "list"
+	case r.method == "POST" && len(segments) == 1:
+		r.action = "create"
+		r.getPath = "/" + r.service + "/%v"
+	case r.method == "PUT":
+		r.action = "update"
+	default:
+		r.action = strings.ToLower(r.method)
+	}
+}
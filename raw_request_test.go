@@ -0,0 +1,218 @@
+package gocardless
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServiceDo(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(b))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /widgets":
+			w.Write([]byte(`{"widgets":[{"id":"WG123","colour":"blue"}],"meta":{"cursors":{"after":"WG123"}}}`))
+		case "GET /widgets/WG404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"not found","type":"invalid_api_usage","code":404,"errors":[{"reason":"resource_not_found"}]}}`))
+		default:
+			w.Write([]byte(`{"widgets":{"id":"WG123","colour":"green"}}`))
+		}
+	}))
+	defer server.Close()
+
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+	ctx := context.TODO()
+
+	var raw json.RawMessage
+	params := struct {
+		Limit int `url:"limit,omitempty"`
+	}{Limit: 10}
+	if err := client.Do(ctx, "GET", "/widgets", params, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != `{"widgets":[{"id":"WG123","colour":"blue"}],"meta":{"cursors":{"after":"WG123"}}}` {
+		t.Fatalf("unexpected raw response %s", raw)
+	}
+	if requests[0].URL.RawQuery != "limit=10" {
+		t.Fatalf("expected params in the query string, got %q", requests[0].URL.RawQuery)
+	}
+	if requests[0].Header.Get("GoCardless-Version") != DefaultAPIVersion || requests[0].Header.Get("Authorization") != "Bearer dummy_token" {
+		t.Fatal("expected the standard headers to be sent")
+	}
+
+	var created struct {
+		Widget struct {
+			ID     string `json:"id"`
+			Colour string `json:"colour"`
+		} `json:"widgets"`
+	}
+	body := map[string]interface{}{"widgets": map[string]string{"colour": "green"}}
+	if err := client.Do(ctx, "POST", "/widgets", body, &created, WithIdempotencyKey("widget-1")); err != nil {
+		t.Fatal(err)
+	}
+	if created.Widget.ID != "WG123" || created.Widget.Colour != "green" {
+		t.Fatalf("unexpected result %+v", created)
+	}
+	if bodies[1] != "{\"widgets\":{\"colour\":\"green\"}}\n" {
+		t.Fatalf("unexpected request body %q", bodies[1])
+	}
+	if requests[1].Header.Get("Idempotency-Key") != "widget-1" || requests[1].Header.Get("Content-Type") != "application/json" {
+		t.Fatal("expected a JSON body with the idempotency key")
+	}
+
+	if err := client.Do(ctx, "GET", "/widgets", url.Values{"colour": {"blue"}}, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if requests[2].URL.RawQuery != "colour=blue" {
+		t.Fatalf("expected url.Values in the query string, got %q", requests[2].URL.RawQuery)
+	}
+
+	err = client.Do(ctx, "GET", "/widgets/WG404", nil, &raw)
+	if !errors.Is(err, ErrResourceNotFound) || StatusCode(err) != http.StatusNotFound {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
+
+// endpointCall is a request seen by the test API in TestServiceDo_MatchesServices
+type endpointCall struct {
+	op     Operation
+	method string
+	path   string
+	signed bool
+}
+
+func TestServiceDo_MatchesServices(t *testing.T) {
+	var calls []endpointCall
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests are signed after middleware runs, so only the API sees
+		// whether they were
+		calls[len(calls)-1].signed = r.Header.Get("Signature") != ""
+		// Conflict on every write, so that any conflict resolution is seen
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":{"type":"invalid_state","code":409,"errors":[{"reason":"idempotent_creation_conflict","links":{"conflicting_resource_id":"ID456"}}]}}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalECPrivateKey(key)
+	signer, err := NewRequestSigner("KEY123", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := func(op Operation, req *http.Request, next RequestHandler) (*http.Response, error) {
+		calls = append(calls, endpointCall{op: op, method: req.Method, path: req.URL.Path})
+		return next(req)
+	}
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithRequestSigner(signer), WithMiddleware(record))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+	ctx := context.TODO()
+	opts := []RequestOption{WithConflictResolution(), WithoutRetries()}
+
+	// Call every method of every service, then make the same request
+	// through Do and expect it to be described and handled the same way
+	services := reflect.ValueOf(client).Elem()
+	for i := 0; i < services.NumField(); i++ {
+		service := services.Field(i)
+		if !services.Type().Field(i).IsExported() || service.Kind() != reflect.Interface {
+			continue
+		}
+		for j := 0; j < service.NumMethod(); j++ {
+			name := services.Type().Field(i).Name + "." + service.Type().Method(j).Name
+			method := service.Method(j)
+			if service.Type().Method(j).Name == "All" {
+				continue
+			}
+			var args []reflect.Value
+			for k := 0; k < method.Type().NumIn()-1; k++ {
+				switch in := method.Type().In(k); in.Kind() {
+				case reflect.Interface:
+					args = append(args, reflect.ValueOf(ctx))
+				case reflect.String:
+					args = append(args, reflect.ValueOf("ID123"))
+				default:
+					args = append(args, reflect.Zero(in))
+				}
+			}
+			for _, opt := range opts {
+				args = append(args, reflect.ValueOf(opt))
+			}
+
+			calls = nil
+			method.Call(args)
+			want := calls
+			if len(want) == 0 {
+				t.Fatalf("%s: no request made", name)
+			}
+
+			calls = nil
+			var raw json.RawMessage
+			client.Do(ctx, want[0].method, want[0].path, nil, &raw, opts...)
+			got := calls
+
+			// Both instalment schedule creates share an endpoint, so Do
+			// cannot tell them apart
+			if want[0].op.Service == "instalment_schedules" && strings.HasPrefix(want[0].op.Action, "create_with_") {
+				want[0].op.Action = "create"
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: expected %+v, got %+v", name, want, got)
+			}
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		method, path              string
+		service, identity, action string
+	}{
+		{"GET", "/payments", "payments", "", "list"},
+		{"GET", "/payments/PM123", "payments", "PM123", "get"},
+		{"POST", "/payments", "payments", "", "create"},
+		{"PUT", "/payments/PM123", "payments", "PM123", "update"},
+		{"POST", "/payments/PM123/actions/cancel", "payments", "PM123", "cancel"},
+		{"POST", "/billing_requests/create_with_actions", "billing_request_with_actions", "", "create_with_actions"},
+		{"POST", "/outbound_payments/withdrawal", "outbound_payments", "", "withdraw"},
+		{"POST", "/branding/logos", "logos", "", "create_for_creditor"},
+		{"GET", "/billing_requests/BRQ123/institutions", "institutions", "BRQ123", "list_for_billing_request"},
+		{"GET", "/widgets/WG123?colour=blue", "widgets", "WG123", "get"},
+	}
+	for _, tt := range tests {
+		r := &apiRequest{method: tt.method, path: tt.path}
+		describe(r)
+		if r.service != tt.service || r.identity != tt.identity || r.action != tt.action {
+			t.Errorf("%s %s: got %s, %s, %s", tt.method, tt.path, r.service, r.identity, r.action)
+		}
+	}
+}
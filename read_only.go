@@ -0,0 +1,47 @@
+package gocardless
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly matches the errors returned by mutating requests made with a
+// read-only config, which are never sent
+var ErrReadOnly = errors.New("client is read-only")
+
+// ReadOnlyError is returned instead of making a mutating request with a
+// read-only config
+type ReadOnlyError struct {
+	Operation Operation
+	Method    string
+	Path      string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("%s %s (%s.%s): %s", e.Method, e.Path, e.Operation.Service, e.Operation.Action, ErrReadOnly)
+}
+
+// Is allows the error to be matched with errors.Is(err, ErrReadOnly)
+func (e *ReadOnlyError) Is(target error) bool {
+	return target == ErrReadOnly
+}
+
+// WithReadOnly makes every request which would change state on the API, such
+// as creates, updates and actions, fail with a ReadOnlyError before it is
+// sent
+func WithReadOnly() ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.readOnly = true
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// ReadOnly reports whether the client was configured with WithReadOnly
+func (s *Service) ReadOnly() bool {
+	c, ok := s.config.(*config)
+	return ok && c.readOnly
+}
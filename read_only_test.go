@@ -0,0 +1,86 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithReadOnly(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"payments":{"id":"PM123"}}`))
+	}))
+	defer server.Close()
+
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+	if !client.ReadOnly() {
+		t.Fatal("expected client to report being read-only")
+	}
+	ctx := context.TODO()
+
+	if _, err := client.Payments.Get(ctx, "PM123"); err != nil {
+		t.Fatal(err)
+	}
+
+	mutations := map[string]func() error{
+		"create": func() error {
+			_, err := client.Payments.Create(ctx, PaymentCreateParams{Amount: 1000})
+			return err
+		},
+		"update": func() error {
+			_, err := client.Payments.Update(ctx, "PM123", PaymentUpdateParams{})
+			return err
+		},
+		"cancel": func() error {
+			_, err := client.Payments.Cancel(ctx, "PM123", PaymentCancelParams{})
+			return err
+		},
+		"mandate cancel": func() error {
+			_, err := client.Mandates.Cancel(ctx, "MD123", MandateCancelParams{})
+			return err
+		},
+	}
+	for name, call := range mutations {
+		err := call()
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", name, err)
+		}
+		var readOnlyErr *ReadOnlyError
+		if !errors.As(err, &readOnlyErr) || readOnlyErr.Method == "GET" {
+			t.Errorf("%s: expected a ReadOnlyError describing the request, got %v", name, err)
+		}
+	}
+
+	if len(requests) != 1 || requests[0] != "GET /payments/PM123" {
+		t.Fatalf("expected only reads to reach the API, got %v", requests)
+	}
+}
+
+func TestWithReadOnly_SharedWithDerivedClients(t *testing.T) {
+	cfg, err := NewConfig("dummy_token", WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+	merchant, err := client.ForToken("merchant_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merchant.ReadOnly() {
+		t.Fatal("expected derived client to be read-only")
+	}
+
+	cfg, _ = NewConfig("dummy_token")
+	client, _ = New(cfg)
+	if client.ReadOnly() {
+		t.Fatal("expected client to be read-write by default")
+	}
+}
@@ -0,0 +1,383 @@
+package gocardless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// apiRequest describes a single call made by one of the services
+type apiRequest struct {
+	// service is the resource collection being called, e.g. "payments"
+	service string
+	// action is the operation performed on it, e.g. "create" or "cancel"
+	action string
+	method string
+	// path is relative to the configured endpoint, with any identity
+	// already interpolated
+	path     string
+	identity string
+	// getPath is the path format of the endpoint fetching the resource a
+	// create makes, used to resolve idempotent creation conflicts
+	getPath string
+	// query is encoded into the URL query string when set
+	query interface{}
+	// body is JSON encoded as the request body when set
+	body interface{}
+	// signed requests are signed by the config's RequestSigner, if any
+	signed bool
+}
+
+// operation describes r to middleware and other hooks
+func (r *apiRequest) operation() Operation {
+	return Operation{
+		Service:  r.service,
+		Action:   r.action,
+		Identity: r.identity,
+	}
+}
+
+// idempotent reports whether r is safe to send again after a network
+// failure, which may happen after the API has already acted on it. Reads and
+// updates are always safe. Other writes are deduplicated by the API using
+// their idempotency key, failing with an idempotent creation conflict if the
+// API already acted on them. This library only resends them if that conflict
+// can be resolved by fetching the resource created, or if the caller chose
+// the key and so can handle the conflict themselves.
+func (r *apiRequest) idempotent(o *requestOptions) bool {
+	if !r.mutating() || r.method == "PUT" || o.callerIdempotencyKey() {
+		return true
+	}
+	return r.getPath != ""
+}
+
+// mutating reports whether the request changes state on the API, which
+// determines whether it is sent with an idempotency key
+func (r *apiRequest) mutating() bool {
+	return r.method != "GET"
+}
+
+// execute performs r against the API described by cfg, decoding the
+// response envelope into out. It owns authentication, the GoCardless
+// headers, idempotency keys, retries and error mapping for every service.
+func execute(ctx context.Context, cfg Config, r *apiRequest, out interface{}, opts []RequestOption) (err error) {
+	// Settings beyond the Config interface only exist on configs made by
+	// NewConfig, and are left unset for other implementations
+	c, ok := cfg.(*config)
+	if !ok {
+		c = &config{}
+	}
+
+	if c.readOnly && r.mutating() {
+		return &ReadOnlyError{
+			Operation: r.operation(),
+			Method:    r.method,
+			Path:      r.path,
+		}
+	}
+
+	uri, err := url.Parse(cfg.Endpoint() + r.path)
+	if err != nil {
+		return err
+	}
+
+	o := &requestOptions{
+		retryPolicy: DefaultRetryPolicy,
+		apiVersion:  APIVersionOf(cfg),
+	}
+	if c.retryPolicy != nil {
+		o.retryPolicy = *c.retryPolicy
+	}
+	for _, opt := range opts {
+		err := opt(o)
+		if err != nil {
+			return err
+		}
+	}
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	var tracer Tracer = noopTracer{}
+	if c.tracer != nil {
+		tracer = c.tracer
+	}
+	name := "gocardless." + r.service + "." + r.action
+	ctx, span := tracer.Start(ctx, name)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+	span.SetAttributes(
+		Attribute{AttributeService, r.service},
+		Attribute{AttributeAction, r.action},
+		Attribute{AttributeMethod, r.method},
+	)
+	if r.identity != "" {
+		span.SetAttributes(Attribute{AttributeIdentity, r.identity})
+	}
+	callerKey := o.callerIdempotencyKey()
+	idempotent := r.idempotent(o)
+	if o.apiVersion == "" {
+		o.apiVersion = DefaultAPIVersion
+	}
+	if r.mutating() && o.idempotencyKey == "" {
+		o.idempotencyKey = NewIdempotencyKey()
+	}
+
+	if r.query != nil {
+		v, err := query.Values(r.query)
+		if err != nil {
+			return err
+		}
+		uri.RawQuery = v.Encode()
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		var buf bytes.Buffer
+		err = json.NewEncoder(&buf).Encode(r.body)
+		if err != nil {
+			return err
+		}
+		body = &buf
+	}
+
+	req, err := http.NewRequest(r.method, uri.String(), body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if o.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+o.accessToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token())
+	}
+	req.Header.Set("GoCardless-Version", o.apiVersion)
+	req.Header.Set("GoCardless-Client-Library", "gocardless-pro-go")
+	req.Header.Set("GoCardless-Client-Version", ClientLibVersion)
+	req.Header.Set("User-Agent", userAgent)
+	if r.mutating() {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", o.idempotencyKey)
+	}
+
+	for key, value := range o.headers {
+		req.Header.Set(key, value)
+	}
+
+	if c.dryRun != nil && r.mutating() {
+		return c.dryRun.record(r, req)
+	}
+
+	var doer Doer = http.DefaultClient
+	if client := cfg.Client(); client != nil {
+		doer = client
+	}
+	if c.doer != nil {
+		doer = c.doer
+	}
+
+	var limiter *RateLimiter
+	var source TokenSource
+	if o.accessToken == "" {
+		limiter = c.rateLimiter
+		source = c.tokenSource
+	} else {
+		limiter = c.limiterFor(o.accessToken)
+	}
+	if o.hasHeader("Authorization") {
+		source = nil
+	}
+	handler := RequestHandler(doer.Do)
+	if r.signed && c.requestSigner != nil {
+		handler = c.requestSigner.signing(handler)
+	}
+	logger := c.logger
+	metrics := c.metrics
+	breaker := c.circuitBreaker(r)
+	send := chain(c.middleware, r.operation(), handler)
+
+	var last *http.Response
+	attempts := 0
+	start := time.Now()
+	reason := ""
+	do := func() (err error) {
+		attempts++
+		last = nil
+		attempt, err := rewind(req)
+		if err != nil {
+			return err
+		}
+
+		ctx, attemptSpan := tracer.Start(ctx, name+".attempt")
+		defer func() {
+			if last != nil {
+				attemptSpan.SetAttributes(Attribute{AttributeStatusCode, last.StatusCode})
+				requestID := RequestID(err)
+				if requestID == "" {
+					requestID = last.Header.Get("X-Request-Id")
+				}
+				if requestID != "" {
+					attemptSpan.SetAttributes(Attribute{AttributeRequestID, requestID})
+				}
+			}
+			if err != nil {
+				attemptSpan.RecordError(err)
+			}
+			attemptSpan.End()
+			reason = retryReason(err)
+		}()
+		attemptSpan.SetAttributes(Attribute{AttributeAttempt, attempts})
+		if reason != "" {
+			attemptSpan.SetAttributes(Attribute{AttributeRetryReason, reason})
+		}
+		attempt = attempt.WithContext(ctx)
+
+		if source != nil {
+			token, err := source.Token(ctx)
+			if err != nil {
+				return fmt.Errorf("getting access token: %w", err)
+			}
+			attempt.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		if limiter != nil {
+			if err := limiter.wait(ctx, o.retryPolicy.MaxWait); err != nil {
+				return err
+			}
+		}
+
+		if breaker != nil {
+			if err := breaker.allow(); err != nil {
+				// Fast failures are reported like any other attempt, so the
+				// breaker's effect shows up in logs and metrics
+				if metrics != nil {
+					recordMetrics(metrics, r, nil, attempts, 0, err)
+				}
+				if logger != nil {
+					logAttempt(ctx, logger, r, attempt, nil, nil, attempts, 0, reason, err)
+				}
+				return err
+			}
+		}
+
+		sent := time.Now()
+		res, err := send(attempt)
+		latency := time.Since(sent)
+		if breaker != nil {
+			breaker.record(res, err)
+		}
+		if metrics != nil {
+			defer func() {
+				recordMetrics(metrics, r, res, attempts, latency, err)
+			}()
+		}
+		if logger != nil {
+			var body []byte
+			if res != nil && logger.Enabled(ctx, slog.LevelDebug) {
+				body = captureBody(res)
+			}
+			defer func() {
+				logAttempt(ctx, logger, r, attempt, res, body, attempts, latency, reason, err)
+			}()
+		}
+		if err != nil {
+			return &transportError{
+				err:        err,
+				idempotent: idempotent,
+			}
+		}
+		defer res.Body.Close()
+		last = res
+
+		if limiter != nil {
+			limiter.observe(res)
+		}
+
+		err = responseErr(res)
+		if err != nil {
+			return err
+		}
+
+		return decodeResponse(res.Body, out)
+	}
+
+	err = try(ctx, o.retryPolicy, do)
+	if refresher, ok := source.(TokenRefresher); ok && StatusCode(err) == http.StatusUnauthorized {
+		// The token may have been rotated, so refresh it and try once more
+		if refreshErr := refresher.Refresh(ctx); refreshErr != nil {
+			err = fmt.Errorf("refreshing access token: %w: %w", refreshErr, err)
+		} else {
+			reason = "access token refreshed"
+			err = do()
+		}
+	}
+	if o.responseInfo != nil {
+		o.responseInfo.record(last, attempts, time.Since(start))
+	}
+	// A conflict on a retry with a key this library generated means an
+	// earlier attempt created the resource, so it is always resolved
+	resolve := o.resolveConflicts || (attempts > 1 && !callerKey)
+	if err != nil && resolve && r.getPath != "" && errors.Is(err, ErrIdempotentCreationConflict) {
+		if id := ConflictingResourceID(err); id != "" {
+			return execute(ctx, cfg, &apiRequest{
+				service:  r.service,
+				action:   "get",
+				method:   "GET",
+				path:     fmt.Sprintf(r.getPath, id),
+				identity: id,
+			}, out, opts)
+		}
+	}
+	return err
+}
+
+// rewind returns a copy of req for a single attempt, with a fresh body so
+// that retries send the full payload rather than an already drained reader
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// decodeResponse decodes a successful response body into out, surfacing
+// any error envelope embedded in it
+func decodeResponse(r io.Reader, out interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Err *APIError `json:"error"`
+	}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return err
+	}
+	if result.Err != nil {
+		return result.Err
+	}
+
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,193 @@
+package gocardless
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signatureLabel names the signature in the Signature-Input and Signature
+// headers
+const signatureLabel = "gc"
+
+// RequestSigner signs requests to endpoints which move money out of the
+// merchant's account, such as creating outbound payments, as HTTP message
+// signatures (RFC 9421)
+type RequestSigner struct {
+	keyID string
+	key   crypto.Signer
+	alg   string
+	now   func() time.Time
+}
+
+// NewRequestSigner returns a RequestSigner for the PEM encoded private key
+// registered with GoCardless under keyID. ECDSA P-256 keys are signed with
+// ecdsa-p256-sha256 and RSA keys with rsa-pss-sha512.
+func NewRequestSigner(keyID string, privateKeyPEM []byte) (*RequestSigner, error) {
+	if keyID == "" {
+		return nil, errors.New("key ID required")
+	}
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+
+	s := &RequestSigner{keyID: keyID, now: time.Now}
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("ECDSA keys must use the P-256 curve")
+		}
+		s.key = k
+		s.alg = "ecdsa-p256-sha256"
+	case *rsa.PrivateKey:
+		s.key = k
+		s.alg = "rsa-pss-sha512"
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return s, nil
+}
+
+// WithRequestSigner signs requests to the endpoints which require it with
+// the given RequestSigner
+func WithRequestSigner(signer *RequestSigner) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.requestSigner = signer
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// signing wraps next so that requests are signed immediately before being
+// sent, once any waiting is over and middleware has finished changing them
+func (s *RequestSigner) signing(next RequestHandler) RequestHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		if err := s.sign(req); err != nil {
+			return nil, err
+		}
+		return next(req)
+	}
+}
+
+// sign sets the Content-Digest, Signature-Input and Signature headers on
+// req, covering its method, target, body and idempotency key
+func (s *RequestSigner) sign(req *http.Request) error {
+	components := []string{"@method", "@authority", "@path"}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		digest := sha512.New()
+		_, err = io.Copy(digest, body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Digest", "sha-512=:"+base64.StdEncoding.EncodeToString(digest.Sum(nil))+":")
+		components = append(components, "content-digest", "content-type")
+	}
+	if req.Header.Get("Idempotency-Key") != "" {
+		components = append(components, "idempotency-key")
+	}
+
+	params := signatureParams(components, s.now().Unix(), s.keyID, s.alg)
+	base := signatureBase(req, components, params)
+
+	var sig []byte
+	var err error
+	switch key := s.key.(type) {
+	case *ecdsa.PrivateKey:
+		h := sha256.Sum256([]byte(base))
+		var r, ss *big.Int
+		r, ss, err = ecdsa.Sign(rand.Reader, key, h[:])
+		if err == nil {
+			// RFC 9421 encodes ECDSA signatures as the fixed size
+			// concatenation of r and s rather than ASN.1
+			sig = make([]byte, 64)
+			r.FillBytes(sig[:32])
+			ss.FillBytes(sig[32:])
+		}
+	case *rsa.PrivateKey:
+		h := sha512.Sum512([]byte(base))
+		sig, err = rsa.SignPSS(rand.Reader, key, crypto.SHA512, h[:], &rsa.PSSOptions{SaltLength: 64})
+	}
+	if err != nil {
+		return fmt.Errorf("signing request: %w", err)
+	}
+
+	req.Header.Set("Signature-Input", signatureLabel+"="+params)
+	req.Header.Set("Signature", signatureLabel+"=:"+base64.StdEncoding.EncodeToString(sig)+":")
+	return nil
+}
+
+// signatureParams serialises the covered components and signature
+// parameters as the value of the Signature-Input header
+func signatureParams(components []string, created int64, keyID, alg string) string {
+	quoted := make([]string, len(components))
+	for i, c := range components {
+		quoted[i] = strconv.Quote(c)
+	}
+	return fmt.Sprintf("(%s);created=%d;keyid=%s;alg=%s",
+		strings.Join(quoted, " "), created, strconv.Quote(keyID), strconv.Quote(alg))
+}
+
+// signatureBase builds the signature base of req over the given components,
+// as defined by RFC 9421 section 2.5
+func signatureBase(req *http.Request, components []string, params string) string {
+	var b strings.Builder
+	for _, c := range components {
+		var value string
+		switch c {
+		case "@method":
+			value = strings.ToUpper(req.Method)
+		case "@authority":
+			value = strings.ToLower(req.URL.Host)
+		case "@path":
+			value = req.URL.EscapedPath()
+			if value == "" {
+				value = "/"
+			}
+		default:
+			value = strings.TrimSpace(req.Header.Get(c))
+		}
+		fmt.Fprintf(&b, "%q: %s\n", c, value)
+	}
+	fmt.Fprintf(&b, "%q: %s", "@signature-params", params)
+	return b.String()
+}
@@ -0,0 +1,226 @@
+package gocardless
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verifySignature checks the message signature on a request received by a
+// test server, rebuilding the signature base from the Signature-Input header
+func verifySignature(r *http.Request, body []byte, pub crypto.PublicKey) error {
+	digest := sha512.Sum512(body)
+	if want := "sha-512=:" + base64.StdEncoding.EncodeToString(digest[:]) + ":"; r.Header.Get("Content-Digest") != want {
+		return fmt.Errorf("content digest %q does not match body", r.Header.Get("Content-Digest"))
+	}
+
+	params, ok := strings.CutPrefix(r.Header.Get("Signature-Input"), "gc=")
+	if !ok {
+		return errors.New("missing signature input")
+	}
+	encoded, ok := strings.CutPrefix(r.Header.Get("Signature"), "gc=:")
+	if !ok {
+		return errors.New("missing signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(encoded, ":"))
+	if err != nil {
+		return err
+	}
+
+	list := params[1:strings.Index(params, ")")]
+	var base strings.Builder
+	for _, c := range strings.Fields(list) {
+		c = strings.Trim(c, `"`)
+		var value string
+		switch c {
+		case "@method":
+			value = r.Method
+		case "@authority":
+			value = r.Host
+		case "@path":
+			value = r.URL.EscapedPath()
+		default:
+			value = r.Header.Get(c)
+		}
+		fmt.Fprintf(&base, "\"%s\": %s\n", c, value)
+	}
+	fmt.Fprintf(&base, "\"@signature-params\": %s", params)
+
+	for _, required := range []string{"@method", "@path", "content-digest", "idempotency-key"} {
+		if !strings.Contains(list, `"`+required+`"`) {
+			return fmt.Errorf("signature does not cover %s", required)
+		}
+	}
+
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if len(sig) != 64 {
+			return fmt.Errorf("unexpected ECDSA signature length %d", len(sig))
+		}
+		h := sha256.Sum256([]byte(base.String()))
+		if !ecdsa.Verify(key, h[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+			return errors.New("invalid ECDSA signature")
+		}
+	case *rsa.PublicKey:
+		h := sha512.Sum512([]byte(base.String()))
+		return rsa.VerifyPSS(key, crypto.SHA512, h[:], sig, &rsa.PSSOptions{SaltLength: 64})
+	}
+	return nil
+}
+
+func signingServer(t *testing.T, pub crypto.PublicKey, signed *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Signature") != "" {
+			if err := verifySignature(r, body, pub); err != nil {
+				t.Errorf("%s %s: %v", r.Method, r.URL.Path, err)
+			}
+			*signed = append(*signed, r.URL.Path)
+		}
+		io.WriteString(w, `{"outbound_payments":{"id":"OUT123"},"outbound_payment_imports":{"id":"IM123"},"payments":{"id":"PM123"}}`)
+	}))
+}
+
+func TestWithRequestSigner(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, _ := x509.MarshalECPrivateKey(ecKey)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaDER, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+
+	cases := []struct {
+		name string
+		pem  []byte
+		pub  crypto.PublicKey
+	}{
+		{"ecdsa", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}), &ecKey.PublicKey},
+		{"rsa", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rsaDER}), &rsaKey.PublicKey},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var signed []string
+			server := signingServer(t, tc.pub, &signed)
+			defer server.Close()
+
+			signer, err := NewRequestSigner("KEY123", tc.pem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithRequestSigner(signer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, _ := New(cfg)
+			ctx := context.TODO()
+
+			if _, err := client.OutboundPayments.Create(ctx, OutboundPaymentCreateParams{Amount: 1000}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.OutboundPayments.Approve(ctx, "OUT123", OutboundPaymentApproveParams{}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.OutboundPayments.Withdraw(ctx, OutboundPaymentWithdrawParams{Amount: 1000}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.OutboundPaymentImports.Create(ctx, OutboundPaymentImportCreateParams{}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.Payments.Create(ctx, PaymentCreateParams{Amount: 1000}); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []string{
+				"/outbound_payments",
+				"/outbound_payments/OUT123/actions/approve",
+				"/outbound_payments/withdrawal",
+				"/outbound_payment_imports",
+			}
+			if strings.Join(signed, ",") != strings.Join(expected, ",") {
+				t.Fatalf("expected only outbound payment endpoints to be signed, got %v", signed)
+			}
+		})
+	}
+}
+
+func TestWithRequestSigner_SignsAfterMiddleware(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalECPrivateKey(key)
+	var signed []string
+	server := signingServer(t, &key.PublicKey, &signed)
+	defer server.Close()
+
+	signer, err := NewRequestSigner("KEY123", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signedAt, delayedUntil time.Time
+	signer.now = func() time.Time {
+		signedAt = time.Now()
+		return signedAt
+	}
+
+	// Delays the request, as waiting for the rate limiter would, and then
+	// changes headers covered by the signature
+	mw := func(op Operation, req *http.Request, next RequestHandler) (*http.Response, error) {
+		time.Sleep(20 * time.Millisecond)
+		delayedUntil = time.Now()
+		req.Header.Set("Idempotency-Key", "changed-by-middleware")
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return next(req)
+	}
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithRequestSigner(signer), WithMiddleware(mw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	if _, err := client.OutboundPayments.Create(context.TODO(), OutboundPaymentCreateParams{Amount: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	if len(signed) != 1 {
+		t.Fatalf("expected the request to be signed, got %v", signed)
+	}
+	if signedAt.Before(delayedUntil) {
+		t.Fatal("expected the request to be signed after being delayed")
+	}
+}
+
+func TestNewRequestSigner_RejectsUnsupportedKeys(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalECPrivateKey(key)
+
+	if _, err := NewRequestSigner("KEY123", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})); err == nil {
+		t.Fatal("expected P-384 keys to be rejected")
+	}
+	if _, err := NewRequestSigner("KEY123", []byte("not a key")); err == nil {
+		t.Fatal("expected invalid PEM to be rejected")
+	}
+}
@@ -0,0 +1,465 @@
+package gocardless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestExecute_SetsHeadersAndEncodesBody(t *testing.T) {
+	var got *http.Request
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"payments":{"id":"PM123"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := getClient(t, server.URL)
+	payment, err := client.Payments.Create(context.TODO(), PaymentCreateParams{Amount: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.Id != "PM123" {
+		t.Fatalf("expected payment PM123, got %q", payment.Id)
+	}
+
+	if got.Method != "POST" || got.URL.Path != "/payments" {
+		t.Fatalf("unexpected request %s %s", got.Method, got.URL.Path)
+	}
+	headers := map[string]string{
+		"Authorization":             "Bearer dummy_token",
+		"GoCardless-Version":        "2015-07-06",
+		"GoCardless-Client-Library": "gocardless-pro-go",
+		"GoCardless-Client-Version": ClientLibVersion,
+		"Content-Type":              "application/json",
+	}
+	for k, v := range headers {
+		if got.Header.Get(k) != v {
+			t.Errorf("expected header %s to be %q, got %q", k, v, got.Header.Get(k))
+		}
+	}
+	if got.Header.Get("Idempotency-Key") == "" {
+		t.Error("expected an idempotency key to be generated")
+	}
+	if p, ok := gotBody["payments"].(map[string]interface{}); !ok || p["amount"] != float64(100) {
+		t.Fatalf("expected body to be enveloped under payments, got %v", gotBody)
+	}
+}
+
+func TestExecute_EncodesQueryForReads(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`{"payments":[],"meta":{"cursors":{}}}`))
+	}))
+	defer server.Close()
+
+	client, _ := getClient(t, server.URL)
+	_, err := client.Payments.List(context.TODO(), PaymentListParams{Customer: "CU123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Query().Get("customer") != "CU123" {
+		t.Fatalf("expected customer query parameter, got %q", got.URL.RawQuery)
+	}
+	if got.Header.Get("Idempotency-Key") != "" {
+		t.Fatal("expected reads to be sent without an idempotency key")
+	}
+}
+
+func TestExecute_EmbeddedErrorEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"error":{"message":"bad things","type":"invalid_api_usage"}}`)
+	}))
+	defer server.Close()
+
+	client, _ := getClient(t, server.URL)
+	_, err := client.Customers.Get(context.TODO(), "CU123")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.Message != "bad things" {
+		t.Fatalf("expected message %q, got %q", "bad things", apiErr.Message)
+	}
+}
+
+// flakyServer fails the first failures requests with a 503, recording the
+// body of every request it receives
+func flakyServer(t *testing.T, failures int, response string, bodies *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading request body: %v", err)
+		}
+		*bodies = append(*bodies, string(b))
+		if len(*bodies) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, `{"error":{"message":"unavailable","type":"gocardless"}}`)
+			return
+		}
+		io.WriteString(w, response)
+	}))
+}
+
+func TestExecute_ReplaysBodyOnRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		call     func(*Service) error
+	}{
+		{
+			name:     "Payments.Create",
+			response: `{"payments":{"id":"PM123"}}`,
+			call: func(s *Service) error {
+				_, err := s.Payments.Create(context.TODO(), PaymentCreateParams{Amount: 100})
+				return err
+			},
+		},
+		{
+			name:     "Payments.Update",
+			response: `{"payments":{"id":"PM123"}}`,
+			call: func(s *Service) error {
+				_, err := s.Payments.Update(context.TODO(), "PM123", PaymentUpdateParams{
+					Metadata: map[string]string{"key": "value"},
+				})
+				return err
+			},
+		},
+		{
+			name:     "BillingRequestWithActions.CreateWithActions",
+			response: `{"billing_request_with_actions":{}}`,
+			call: func(s *Service) error {
+				_, err := s.BillingRequestWithActions.CreateWithActions(context.TODO(), BillingRequestWithActionCreateWithActionsParams{
+					Links: &BillingRequestWithActionCreateWithActionsParamsLinks{Creditor: "CR123"},
+				})
+				return err
+			},
+		},
+		{
+			name:     "OutboundPaymentImports.Create",
+			response: `{"outbound_payment_imports":{"id":"IM123"}}`,
+			call: func(s *Service) error {
+				_, err := s.OutboundPaymentImports.Create(context.TODO(), OutboundPaymentImportCreateParams{
+					Links: &OutboundPaymentImportCreateParamsLinks{Creditor: "CR123"},
+				})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			server := flakyServer(t, 2, tt.response, &bodies)
+			defer server.Close()
+
+			// Read the body in middleware, as an auditing hook would, so
+			// that each attempt depends on the body being rebuilt rather
+			// than on net/http rewinding it
+			var audited []string
+			audit := func(op Operation, req *http.Request, next RequestHandler) (*http.Response, error) {
+				b, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				audited = append(audited, string(b))
+				req.Body = io.NopCloser(bytes.NewReader(b))
+				return next(req)
+			}
+			config, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithMiddleware(audit))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, _ := New(config)
+			if err := tt.call(client); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(bodies) != 3 {
+				t.Fatalf("expected 3 attempts, got %d", len(bodies))
+			}
+			for i := range bodies {
+				if bodies[i] == "" || bodies[i] != bodies[0] {
+					t.Fatalf("attempt %d sent body %q, expected %q", i+1, bodies[i], bodies[0])
+				}
+				if audited[i] != bodies[0] {
+					t.Fatalf("attempt %d passed body %q to middleware, expected %q", i+1, audited[i], bodies[0])
+				}
+			}
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestExecute_RetriesTransientTransportErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		call      func(*Service) error
+		wantCalls int
+	}{
+		{
+			name: "get retried after unexpected EOF",
+			err:  io.ErrUnexpectedEOF,
+			call: func(s *Service) error {
+				_, err := s.Payments.Get(context.TODO(), "PM123")
+				return err
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "create retried after connection reset",
+			err:  syscall.ECONNRESET,
+			call: func(s *Service) error {
+				_, err := s.Payments.Create(context.TODO(), PaymentCreateParams{})
+				return err
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "create without get endpoint not retried",
+			err:  syscall.ECONNRESET,
+			call: func(s *Service) error {
+				_, err := s.MandatePdfs.Create(context.TODO(), MandatePdfCreateParams{})
+				return err
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "action without key not retried",
+			err:  syscall.ECONNRESET,
+			call: func(s *Service) error {
+				_, err := s.Webhooks.Retry(context.TODO(), "WB123")
+				return err
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "action with caller key retried",
+			err:  syscall.ECONNRESET,
+			call: func(s *Service) error {
+				_, err := s.Payments.Cancel(context.TODO(), "PM123", PaymentCancelParams{}, WithIdempotencyKey("key"))
+				return err
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "permanent error not retried",
+			err:  errors.New("malformed request"),
+			call: func(s *Service) error {
+				_, err := s.Payments.Get(context.TODO(), "PM123")
+				return err
+			},
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return nil, tt.err
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"payments":{},"webhooks":{}}`)),
+				}, nil
+			})
+
+			config, err := NewConfig("dummy_token", WithClient(&http.Client{Transport: transport}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, _ := New(config)
+
+			err = tt.call(client)
+			if calls != tt.wantCalls {
+				t.Fatalf("expected %d attempts, got %d", tt.wantCalls, calls)
+			}
+			if tt.wantCalls == 1 && !errors.Is(err, tt.err) {
+				t.Fatalf("expected transport error to be returned, got %v", err)
+			}
+		})
+	}
+}
+
+func conflictServer(t *testing.T, collection, id string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			if r.URL.Path != "/"+collection+"/"+id {
+				t.Errorf("unexpected follow-up request to %s", r.URL.Path)
+			}
+			fmt.Fprintf(w, `{%q:{"id":%q}}`, collection, id)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprintf(w, `{"error":{"message":"A resource has already been created with this idempotency key","type":"invalid_state","code":409,"errors":[{"reason":"idempotent_creation_conflict","message":"A resource has already been created with this idempotency key","links":{"conflicting_resource_id":%q}}]}}`, id)
+	}))
+}
+
+func TestExecute_ConflictResolution(t *testing.T) {
+	tests := []struct {
+		collection string
+		id         string
+		create     func(*Service, ...RequestOption) (string, error)
+	}{
+		{"payments", "PM123", func(s *Service, opts ...RequestOption) (string, error) {
+			p, err := s.Payments.Create(context.TODO(), PaymentCreateParams{}, opts...)
+			if err != nil {
+				return "", err
+			}
+			return p.Id, nil
+		}},
+		{"refunds", "RF123", func(s *Service, opts ...RequestOption) (string, error) {
+			r, err := s.Refunds.Create(context.TODO(), RefundCreateParams{}, opts...)
+			if err != nil {
+				return "", err
+			}
+			return r.Id, nil
+		}},
+		{"subscriptions", "SB123", func(s *Service, opts ...RequestOption) (string, error) {
+			sb, err := s.Subscriptions.Create(context.TODO(), SubscriptionCreateParams{}, opts...)
+			if err != nil {
+				return "", err
+			}
+			return sb.Id, nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.collection, func(t *testing.T) {
+			server := conflictServer(t, tt.collection, tt.id)
+			defer server.Close()
+			client, _ := getClient(t, server.URL)
+
+			_, err := tt.create(client, WithIdempotencyKey("key"))
+			if !errors.Is(err, ErrIdempotentCreationConflict) {
+				t.Fatalf("expected conflict error without resolution, got %v", err)
+			}
+
+			id, err := tt.create(client, WithIdempotencyKey("key"), WithConflictResolution())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tt.id {
+				t.Fatalf("expected existing resource %s, got %q", tt.id, id)
+			}
+		})
+	}
+}
+
+func TestExecute_ClassifierCannotResendUnsafeRequests(t *testing.T) {
+	calls := 0
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return nil, syscall.ECONNRESET
+	})
+	config, err := NewConfig("dummy_token",
+		WithClient(&http.Client{Transport: transport}),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts: 3,
+			Retryable: func(statusCode int, err error) bool {
+				return true
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(config)
+
+	if _, err := client.Webhooks.Retry(context.TODO(), "WB123"); !errors.Is(err, syscall.ECONNRESET) {
+		t.Fatalf("expected transport error to be returned, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the action not to be resent, got %d attempts", calls)
+	}
+}
+
+func TestExecute_ResolvesConflictAfterResetAfterSuccess(t *testing.T) {
+	created := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			io.WriteString(w, `{"payments":{"id":"PM123"}}`)
+			return
+		}
+		key := r.Header.Get("Idempotency-Key")
+		if created[key] {
+			w.WriteHeader(http.StatusConflict)
+			io.WriteString(w, `{"error":{"message":"conflict","type":"invalid_state","code":409,"errors":[{"reason":"idempotent_creation_conflict","message":"conflict","links":{"conflicting_resource_id":"PM123"}}]}}`)
+			return
+		}
+		created[key] = true
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"payments":{"id":"PM123"}}`)
+	}))
+	defer server.Close()
+
+	// The first create succeeds on the server, but its response is lost
+	posts := 0
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		res, err := http.DefaultTransport.RoundTrip(req)
+		if err == nil && req.Method == "POST" {
+			posts++
+			if posts == 1 {
+				res.Body.Close()
+				return nil, syscall.ECONNRESET
+			}
+		}
+		return res, err
+	})
+	config, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(config)
+
+	payment, err := client.Payments.Create(context.TODO(), PaymentCreateParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payment.Id != "PM123" {
+		t.Fatalf("expected the payment created by the first attempt, got %+v", payment)
+	}
+
+	// A caller who chose the key still sees the conflict, as they may have
+	// reused it
+	posts = 1
+	_, err = client.Payments.Create(context.TODO(), PaymentCreateParams{}, WithIdempotencyKey("caller-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Payments.Create(context.TODO(), PaymentCreateParams{}, WithIdempotencyKey("caller-key"))
+	if !errors.Is(err, ErrIdempotentCreationConflict) {
+		t.Fatalf("expected a conflict for a reused caller key, got %v", err)
+	}
+}
+
+func TestExecute_ConflictResolutionWithoutGetEndpoint(t *testing.T) {
+	server := conflictServer(t, "mandate_pdfs", "MP123")
+	defer server.Close()
+	client, _ := getClient(t, server.URL)
+
+	_, err := client.MandatePdfs.Create(context.TODO(), MandatePdfCreateParams{}, WithConflictResolution())
+	if !errors.Is(err, ErrIdempotentCreationConflict) {
+		t.Fatalf("expected conflict error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package gocardless
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseInfo holds metadata about the response to a request
+type ResponseInfo struct {
+	// StatusCode is the HTTP status code of the final response
+	StatusCode int
+	// Header holds the headers of the final response
+	Header http.Header
+	// RequestID is the ID the API assigned to the request, for use when
+	// contacting support
+	RequestID string
+	// RateLimit is the rate limit state reported by the API
+	RateLimit RateLimitState
+	// Attempts is the number of attempts made, including retries
+	Attempts int
+	// Latency is the time taken to complete the request, including retries
+	Latency time.Duration
+}
+
+// WithResponseInfo fills info with metadata about the response once the
+// request completes, whether or not it succeeded. When paging with an
+// iterator, info describes the most recently fetched page.
+func WithResponseInfo(info *ResponseInfo) RequestOption {
+	return func(opts *requestOptions) error {
+		opts.responseInfo = info
+		return nil
+	}
+}
+
+// record fills info from the final response to a request, which is nil if
+// no response was received
+func (info *ResponseInfo) record(res *http.Response, attempts int, latency time.Duration) {
+	*info = ResponseInfo{
+		Attempts: attempts,
+		Latency:  latency,
+	}
+	if res == nil {
+		return
+	}
+	info.StatusCode = res.StatusCode
+	info.Header = res.Header
+	info.RequestID = res.Header.Get("X-Request-Id")
+	info.RateLimit.Limit, _ = strconv.Atoi(res.Header.Get("RateLimit-Limit"))
+	info.RateLimit.Remaining, _ = strconv.Atoi(res.Header.Get("RateLimit-Remaining"))
+	info.RateLimit.Reset, _ = parseRateLimitReset(res.Header.Get("RateLimit-Reset"))
+}
@@ -0,0 +1,82 @@
+package gocardless
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithResponseInfo(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-Request-Id", fmt.Sprintf("RQ%d", calls))
+		w.Header().Set("RateLimit-Limit", "1000")
+		w.Header().Set("RateLimit-Remaining", "990")
+		w.Header().Set("Deprecation", "true")
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"error":"bad gateway"}`))
+			return
+		}
+		w.Write([]byte(`{"customers":{"id":"CU123"}}`))
+	}))
+	defer server.Close()
+
+	client, _ := getClient(t, server.URL)
+	var info ResponseInfo
+	_, err := client.Customers.Get(context.TODO(), "CU123", WithResponseInfo(&info))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", info.StatusCode)
+	}
+	if info.RequestID != "RQ2" {
+		t.Errorf("expected request ID of the final attempt, got %q", info.RequestID)
+	}
+	if info.Attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", info.Attempts)
+	}
+	if info.RateLimit.Limit != 1000 || info.RateLimit.Remaining != 990 {
+		t.Errorf("unexpected rate limit state %+v", info.RateLimit)
+	}
+	if info.Header.Get("Deprecation") != "true" {
+		t.Errorf("expected response headers to be exposed")
+	}
+	if info.Latency <= 0 {
+		t.Errorf("expected latency to be recorded")
+	}
+}
+
+func TestWithResponseInfo_PagingIterator(t *testing.T) {
+	page := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page++
+		w.Header().Set("X-Request-Id", fmt.Sprintf("RQ%d", page))
+		after := ""
+		if page == 1 {
+			after = "CU123"
+		}
+		fmt.Fprintf(w, `{"customers":[{"id":"CU%d"}],"meta":{"cursors":{"after":%q}}}`, page, after)
+	}))
+	defer server.Close()
+
+	client, _ := getClient(t, server.URL)
+	var info ResponseInfo
+	iter := client.Customers.All(context.TODO(), CustomerListParams{}, WithResponseInfo(&info))
+	for i := 1; iter.Next(); i++ {
+		if _, err := iter.Value(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("RQ%d", i); info.RequestID != want {
+			t.Fatalf("expected info for page %d to have request ID %s, got %q", i, want, info.RequestID)
+		}
+	}
+	if page != 2 {
+		t.Fatalf("expected 2 pages, got %d", page)
+	}
+}
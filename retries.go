@@ -1,12 +1,19 @@
 package gocardless
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand"
+	"net"
 	"net/http"
 	"strconv"
+	"syscall"
 	"time"
 )
 
@@ -14,28 +21,143 @@ type temporary interface {
 	Temporary() bool
 }
 
-type waiter interface {
-	Wait()
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made, including the first
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubling with each
+	// subsequent attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, if set
+	MaxDelay time.Duration
+	// Jitter picks each delay at random between zero and its computed value
+	Jitter bool
+	// RespectRetryAfter waits for at least as long as the API asks via the
+	// Retry-After header, or until RateLimit-Reset once the rate limit is
+	// exhausted
+	RespectRetryAfter bool
+	// MaxWait is the longest the client will wait before a retry. If the
+	// API asks it to wait longer, the last error is returned instead.
+	MaxWait time.Duration
+	// Retryable decides whether a failed attempt should be retried, given
+	// its HTTP status code (zero if no response was received) and error.
+	// By default server errors and rate limited requests are retried, as
+	// are transient network failures of requests which are safe to resend.
+	Retryable func(statusCode int, err error) bool
 }
 
-func try(attempts int, fn func() error) error {
-	var err error
+// DefaultRetryPolicy makes up to three attempts at each request, retrying
+// server errors and rate limited requests immediately unless the API asks
+// the client to wait
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:       3,
+	RespectRetryAfter: true,
+}
+
+// WithRetryPolicy configures how failed requests are retried
+func WithRetryPolicy(policy RetryPolicy) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.retryPolicy = &policy
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	var te *transportError
+	if errors.As(err, &te) && !te.idempotent {
+		// Whatever the classifier says, the request may have been acted on
+		return false
+	}
+	if p.Retryable == nil {
+		t, ok := err.(temporary)
+		return ok && t.Temporary()
+	}
+	var statusCode int
+	var re *responseError
+	if errors.As(err, &re) {
+		statusCode = re.res.StatusCode
+	}
+	return p.Retryable(statusCode, err)
+}
+
+// maxBackoff is the longest delay that can be doubled without overflowing
+const maxBackoff = time.Duration(math.MaxInt64 / 2)
+
+// delay returns how long to wait before retrying after the given attempt
+// failed with err
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	var d time.Duration
+	if p.BaseDelay > 0 {
+		d = p.BaseDelay
+		for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay) && d <= maxBackoff; i++ {
+			d *= 2
+		}
+		if p.MaxDelay > 0 && d > p.MaxDelay {
+			d = p.MaxDelay
+		}
+		if p.Jitter && d > 0 {
+			n := int64(d)
+			if n < math.MaxInt64 {
+				n++
+			}
+			d = time.Duration(mathrand.Int63n(n))
+		}
+	}
+
+	var re *responseError
+	if p.RespectRetryAfter && errors.As(err, &re) {
+		if wait := re.retryAfter(); wait > d {
+			d = wait
+		}
+	}
+	return d
+}
+
+// try calls fn until it succeeds or policy gives up, waiting between
+// attempts. Waits end early if ctx is done, in which case the context's
+// error is returned wrapped together with the last error from fn.
+func try(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	attempts := policy.MaxAttempts
 	if attempts < 1 {
 		attempts = 1
 	}
-	for i := attempts; i > 0; i-- {
+	var err error
+	for attempt := 1; ; attempt++ {
 		err = fn()
 		if err == nil {
 			return nil
 		}
-		if t, ok := err.(temporary); !ok || !t.Temporary() {
+		if attempt >= attempts || !policy.retryable(err) {
 			return err
 		}
-		if w, ok := err.(waiter); ok {
-			w.Wait()
+
+		wait := policy.delay(attempt, err)
+		if policy.MaxWait > 0 && wait > policy.MaxWait {
+			return fmt.Errorf("retry wait of %v exceeds maximum of %v: %w", wait, policy.MaxWait, err)
+		}
+		if ctxErr := sleep(ctx, wait); ctxErr != nil {
+			return fmt.Errorf("%w: %w", ctxErr, err)
 		}
 	}
-	return err
+}
+
+// sleep waits for d, returning ctx.Err() if ctx is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
 }
 
 // FlexError encapsulates an error response that may be a string or an object
@@ -68,7 +190,10 @@ func responseErr(r *http.Response) error {
 
 		err := json.NewDecoder(r.Body).Decode(&result)
 		if err != nil {
-			return fmt.Errorf("decoding error response: %w", err)
+			return &responseError{
+				res: r,
+				err: fmt.Errorf("decoding error response: %w", err),
+			}
 		}
 
 		if result.Error.Err != nil {
@@ -119,20 +244,64 @@ func (r *responseError) Temporary() bool {
 	}
 }
 
-func (r *responseError) Wait() {
+// retryAfter returns how long the API has asked the client to wait before
+// trying again, if at all
+func (r *responseError) retryAfter() time.Duration {
+	if v := r.res.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return time.Until(t)
+		}
+	}
+
 	rem, err := strconv.Atoi(r.res.Header.Get("RateLimit-Remaining"))
 	if err != nil || rem > 0 {
-		return
+		return 0
 	}
-	reset := r.res.Header.Get("RateLimit-Reset")
-	t, err := time.Parse(time.RFC1123, reset)
+	t, err := parseRateLimitReset(r.res.Header.Get("RateLimit-Reset"))
 	if err != nil {
-		t, err = time.Parse(time.RFC1123Z, reset)
+		return 0
 	}
-	if err != nil {
-		return
+	return time.Until(t)
+}
+
+// transportError is returned when a request fails without a response from
+// the API, such as when the connection is reset
+type transportError struct {
+	err error
+	// idempotent is set if the request is safe to send again
+	idempotent bool
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+func (e *transportError) Temporary() bool {
+	return e.idempotent && transient(e.err)
+}
+
+// transient reports whether err is a network failure that may succeed if
+// the request is sent again
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
 	}
-	time.Sleep(time.Until(t))
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
 
 // NewIdempotencyKey generates a random and unique idempotency key
@@ -1,12 +1,14 @@
 package gocardless
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestFlexError_UnmarshalJSON_String(t *testing.T) {
@@ -209,3 +211,175 @@ func TestResponseError_Temporary(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := policy.delay(i+1, errors.New("failed")); got != w {
+			t.Errorf("delay after attempt %d: got %v, want %v", i+1, got, w)
+		}
+	}
+
+	policy.Jitter = true
+	for i := 0; i < 100; i++ {
+		if got := policy.delay(3, errors.New("failed")); got < 0 || got > 300*time.Millisecond {
+			t.Fatalf("jittered delay %v out of range", got)
+		}
+	}
+}
+
+func TestRetryPolicy_DelayWithoutMaxDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 50, BaseDelay: time.Second}
+	for attempt := 1; attempt <= 100; attempt++ {
+		if got := policy.delay(attempt, errors.New("failed")); got <= 0 {
+			t.Fatalf("delay after attempt %d overflowed to %v", attempt, got)
+		}
+	}
+
+	policy.Jitter = true
+	for attempt := 1; attempt <= 100; attempt++ {
+		if got := policy.delay(attempt, errors.New("failed")); got < 0 {
+			t.Fatalf("jittered delay after attempt %d overflowed to %v", attempt, got)
+		}
+	}
+}
+
+func TestRetryPolicy_DelayRespectsRetryAfter(t *testing.T) {
+	err := &responseError{res: &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"2"}},
+	}}
+
+	policy := RetryPolicy{BaseDelay: time.Millisecond, RespectRetryAfter: true}
+	if got := policy.delay(1, err); got != 2*time.Second {
+		t.Fatalf("expected Retry-After to be honoured, got %v", got)
+	}
+
+	policy.RespectRetryAfter = false
+	if got := policy.delay(1, err); got != time.Millisecond {
+		t.Fatalf("expected Retry-After to be ignored, got %v", got)
+	}
+}
+
+func TestTry_StopsAtMaxAttempts(t *testing.T) {
+	calls := 0
+	err := try(context.TODO(), RetryPolicy{MaxAttempts: 4}, func() error {
+		calls++
+		return &responseError{res: &http.Response{StatusCode: http.StatusBadGateway}}
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 4 {
+		t.Fatalf("expected 4 attempts, got %d", calls)
+	}
+}
+
+func TestTry_CustomClassifier(t *testing.T) {
+	var codes []int
+	calls := 0
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		Retryable: func(statusCode int, err error) bool {
+			codes = append(codes, statusCode)
+			return statusCode == http.StatusConflict
+		},
+	}
+	try(context.TODO(), policy, func() error {
+		calls++
+		return &responseError{res: &http.Response{StatusCode: http.StatusConflict}}
+	})
+	if calls != 3 {
+		t.Fatalf("expected classifier to retry 409s, got %d attempts", calls)
+	}
+
+	calls = 0
+	try(context.TODO(), policy, func() error {
+		calls++
+		return &responseError{res: &http.Response{StatusCode: http.StatusInternalServerError}}
+	})
+	if calls != 1 {
+		t.Fatalf("expected classifier to stop retrying 500s, got %d attempts", calls)
+	}
+	if codes[len(codes)-1] != http.StatusInternalServerError {
+		t.Fatalf("expected classifier to receive the status code, got %v", codes)
+	}
+}
+
+func TestWithRetryPolicy_AppliesGloballyAndPerRequest(t *testing.T) {
+	var bodies []string
+	server := flakyServer(t, 10, `{"payments":{"id":"PM123"}}`, &bodies)
+	defer server.Close()
+
+	config, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(config)
+
+	client.Payments.Get(context.TODO(), "PM123")
+	if len(bodies) != 2 {
+		t.Fatalf("expected global policy to make 2 attempts, got %d", len(bodies))
+	}
+
+	bodies = nil
+	client.Payments.Get(context.TODO(), "PM123", WithRequestRetryPolicy(RetryPolicy{MaxAttempts: 5}))
+	if len(bodies) != 5 {
+		t.Fatalf("expected request policy to make 5 attempts, got %d", len(bodies))
+	}
+}
+
+func TestTry_WaitEndsWhenContextIsDone(t *testing.T) {
+	apiErr := &APIError{Message: "rate limited"}
+	rateLimited := &responseError{
+		res: &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header: http.Header{
+				"Ratelimit-Remaining": []string{"0"},
+				"Ratelimit-Reset":     []string{time.Now().Add(time.Hour).UTC().Format(time.RFC1123)},
+			},
+		},
+		err: apiErr,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := try(ctx, DefaultRetryPolicy, func() error {
+		return rateLimited
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected wait to end with the context, took %v", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context error, got %v", err)
+	}
+	var got *APIError
+	if !errors.As(err, &got) || got != apiErr {
+		t.Fatalf("expected last API error to be wrapped, got %v", err)
+	}
+}
+
+func TestTry_MaxWait(t *testing.T) {
+	rateLimited := &responseError{res: &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"3600"}},
+	}}
+
+	calls := 0
+	policy := DefaultRetryPolicy
+	policy.MaxWait = time.Second
+	err := try(context.TODO(), policy, func() error {
+		calls++
+		return rateLimited
+	})
+	if calls != 1 {
+		t.Fatalf("expected to give up rather than wait, got %d attempts", calls)
+	}
+	var re *responseError
+	if !errors.As(err, &re) {
+		t.Fatalf("expected last error to be returned, got %v", err)
+	}
+}
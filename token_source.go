@@ -0,0 +1,40 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+)
+
+// TokenSource supplies the access token for each request, allowing it to be
+// rotated without rebuilding the client
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenRefresher may be implemented by a TokenSource to be told when the API
+// rejects its token. Refresh is called before the request is retried once,
+// with the token the source then returns.
+type TokenRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// TokenSourceFunc can be used to convert a function into a TokenSource
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token will call the TokenSourceFunc function
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// WithTokenSource takes the access token for each request from source rather
+// than the token the config was created with, which may then be empty
+func WithTokenSource(source TokenSource) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.tokenSource = source
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
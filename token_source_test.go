@@ -0,0 +1,128 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// rotatingSource returns its current token, only picking up the latest one
+// from the vault when refreshed
+type rotatingSource struct {
+	mu        sync.Mutex
+	current   string
+	vault     string
+	refreshes int
+	err       error
+}
+
+func (s *rotatingSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current, nil
+}
+
+func (s *rotatingSource) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshes++
+	if s.err != nil {
+		return s.err
+	}
+	s.current = s.vault
+	return nil
+}
+
+func tokenServer(valid string, tokens *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*tokens = append(*tokens, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer "+valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":{"message":"Access token not active","type":"invalid_api_usage","code":401,"errors":[{"reason":"access_token_not_active"}]}}`)
+			return
+		}
+		io.WriteString(w, `{"customers":{"id":"CU123"}}`)
+	}))
+}
+
+func TestWithTokenSource_ConsultedOnEachRequest(t *testing.T) {
+	var tokens []string
+	server := tokenServer("token_b", &tokens)
+	defer server.Close()
+
+	token := "token_a"
+	source := TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return token, nil
+	})
+	config, err := NewConfig("", WithEndpoint(server.URL), WithTokenSource(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(config)
+
+	if _, err := client.Customers.Get(context.TODO(), "CU123"); StatusCode(err) != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized with the old token, got %v", err)
+	}
+	token = "token_b"
+	if _, err := client.Customers.Get(context.TODO(), "CU123"); err != nil {
+		t.Fatalf("expected rotated token to be used, got %v", err)
+	}
+}
+
+func TestWithTokenSource_RefreshesOnUnauthorized(t *testing.T) {
+	var tokens []string
+	server := tokenServer("token_b", &tokens)
+	defer server.Close()
+
+	source := &rotatingSource{current: "token_a", vault: "token_b"}
+	config, err := NewConfig("", WithEndpoint(server.URL), WithTokenSource(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(config)
+
+	if _, err := client.Customers.Get(context.TODO(), "CU123"); err != nil {
+		t.Fatal(err)
+	}
+	if source.refreshes != 1 {
+		t.Fatalf("expected a single refresh, got %d", source.refreshes)
+	}
+	if len(tokens) != 2 || tokens[0] != "Bearer token_a" || tokens[1] != "Bearer token_b" {
+		t.Fatalf("expected one retry with the refreshed token, got %v", tokens)
+	}
+}
+
+func TestWithTokenSource_RefreshFailure(t *testing.T) {
+	var tokens []string
+	server := tokenServer("token_b", &tokens)
+	defer server.Close()
+
+	vaultErr := errors.New("vault unavailable")
+	source := &rotatingSource{current: "token_a", err: vaultErr}
+	config, _ := NewConfig("", WithEndpoint(server.URL), WithTokenSource(source))
+	client, _ := New(config)
+
+	_, err := client.Customers.Get(context.TODO(), "CU123")
+	if !errors.Is(err, vaultErr) || StatusCode(err) != http.StatusUnauthorized {
+		t.Fatalf("expected refresh and API errors to be returned, got %v", err)
+	}
+	if len(tokens) != 1 {
+		t.Fatalf("expected no retry after a failed refresh, got %d requests", len(tokens))
+	}
+}
+
+func TestNewConfig_RequiresTokenOrSource(t *testing.T) {
+	if _, err := NewConfig(""); err == nil {
+		t.Fatal("expected error without a token or token source")
+	}
+	source := TokenSourceFunc(func(ctx context.Context) (string, error) {
+		return "token", nil
+	})
+	if _, err := NewConfig("", WithTokenSource(source)); err != nil {
+		t.Fatalf("expected token source to be accepted in place of a token, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package gocardless
+
+import (
+	"context"
+	"errors"
+)
+
+// Tracer starts spans for the requests made by the services. Each call to a
+// service is traced by a span named after it, such as
+// "gocardless.payments.create", with a child span for each attempt at the
+// request, named with an ".attempt" suffix.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single operation traced by a Tracer
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key value pair describing a Span. Values are strings, ints
+// or bools.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attribute keys set on spans
+const (
+	AttributeService     = "gocardless.service"
+	AttributeAction      = "gocardless.action"
+	AttributeIdentity    = "gocardless.identity"
+	AttributeAttempt     = "gocardless.attempt"
+	AttributeRequestID   = "gocardless.request_id"
+	AttributeRetryReason = "gocardless.retry_reason"
+	AttributeMethod      = "http.request.method"
+	AttributeStatusCode  = "http.response.status_code"
+)
+
+// WithTracer traces every request made with the config with tracer
+func WithTracer(tracer Tracer) ConfigOption {
+	return func(cfg Config) error {
+		if c, ok := cfg.(*config); ok {
+			c.tracer = tracer
+		} else {
+			return errors.New("invalid input, input is not of type config")
+		}
+		return nil
+	}
+}
+
+// noopTracer is used when no Tracer is configured
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+
+func (noopSpan) RecordError(err error) {}
+
+func (noopSpan) End() {}
@@ -0,0 +1,106 @@
+package gocardless
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+type spanKey struct{}
+
+// recordingTracer records the spans it starts, and the parent of each
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	errs   []error
+	ended  bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attrs: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordedSpan) End() {
+	s.ended = true
+}
+
+func TestWithTracer(t *testing.T) {
+	var bodies []string
+	server := flakyServer(t, 1, `{"payments":{"id":"PM123"}}`, &bodies)
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+	var seen []*recordedSpan
+	observe := func(op Operation, req *http.Request, next RequestHandler) (*http.Response, error) {
+		span, _ := req.Context().Value(spanKey{}).(*recordedSpan)
+		seen = append(seen, span)
+		return next(req)
+	}
+	cfg, err := NewConfig("dummy_token", WithEndpoint(server.URL), WithTracer(tracer), WithMiddleware(observe))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(cfg)
+
+	if _, err := client.Payments.Create(context.TODO(), PaymentCreateParams{Amount: 1000}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected a span for the call and each attempt, got %d", len(tracer.spans))
+	}
+	call, first, second := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+	if call.name != "gocardless.payments.create" || call.parent != nil {
+		t.Fatalf("unexpected call span %+v", call)
+	}
+	if call.attrs[AttributeService] != "payments" || call.attrs[AttributeAction] != "create" {
+		t.Fatalf("unexpected call span attributes %v", call.attrs)
+	}
+	for i, attempt := range []*recordedSpan{first, second} {
+		if attempt.name != "gocardless.payments.create.attempt" || attempt.parent != call {
+			t.Fatalf("expected attempt span to be a child of the call span, got %+v", attempt)
+		}
+		if attempt.attrs[AttributeAttempt] != i+1 {
+			t.Fatalf("expected attempt %d, got %v", i+1, attempt.attrs[AttributeAttempt])
+		}
+		if seen[i] != attempt {
+			t.Fatal("expected the attempt span to be in the request context")
+		}
+	}
+	if first.attrs[AttributeStatusCode] != 503 || len(first.errs) != 1 {
+		t.Fatalf("expected the failed attempt to be recorded, got %+v", first)
+	}
+	if second.attrs[AttributeStatusCode] != 200 || second.attrs[AttributeRetryReason] != "status 503" {
+		t.Fatalf("unexpected retry attempt span %+v", second)
+	}
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Fatalf("expected span %s to be ended", span.name)
+		}
+	}
+	if len(call.errs) != 0 {
+		t.Fatalf("expected no error on the successful call, got %v", call.errs)
+	}
+}